@@ -2,6 +2,7 @@ package nntpclient
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/textproto"
@@ -31,6 +32,21 @@ func (c *Client) ArticleAsBytes(id string) (textproto.MIMEHeader, []byte, error)
 // part of the article body was read, if any, will have been written to the
 // writer, nil will be returned for the headers, and the error will be returned.
 func (c *Client) Article(id string, writer io.Writer) (textproto.MIMEHeader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	headers, err := c.article(id, writer)
+	if err != nil {
+		return nil, err
+	}
+	return headers.MIMEHeader(), nil
+}
+
+func (c *Client) article(id string, writer io.Writer) (HeaderBlock, error) {
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
+	}
+
 	var cmd string
 	if id == "" {
 		cmd = "ARTICLE"
@@ -57,9 +73,15 @@ func (c *Client) Article(id string, writer io.Writer) (textproto.MIMEHeader, err
 	if code != 220 {
 		return nil, UnexpectedError(code, message)
 	}
+	c.trackArticle(id, message)
 
 	headers, err := c.readHeaders()
 	if err != nil {
+		var parseErr *HeaderParseError
+		if errors.As(err, &parseErr) {
+			// Consume the body so that the connection remains usable.
+			c.readBody(io.Discard)
+		}
 		return nil, err
 	}
 
@@ -128,7 +128,11 @@ func Test_Article(t *testing.T) {
 
 	t.Run("handles error reading headers", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
-			writeLines(c, "220 article", "\tbad: header")
+			if cmd == "date" {
+				writeLines(c, "111 20231112130000")
+				return
+			}
+			writeLines(c, "220 article", "\tbad: header", "", "body", ".")
 		}
 
 		server, client := getServerAndClient(t, handler)
@@ -139,6 +143,13 @@ func Test_Article(t *testing.T) {
 		assert.Empty(t, body)
 		assert.Nil(t, headers)
 		assert.ErrorContains(t, err, "malformed headers, found folded")
+
+		var parseErr *HeaderParseError
+		assert.Equal(t, true, errors.As(err, &parseErr))
+
+		// The remainder of the article has been consumed.
+		_, err = client.Date()
+		assert.Nil(t, err)
 	})
 
 	t.Run("handles error reading body", func(t *testing.T) {
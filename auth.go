@@ -3,7 +3,25 @@ package nntpclient
 // Authenticate provides simple username and password authentication through
 // the AUTHINFO extension (RFC 4643). The absence of an error indicates
 // successful authentication.
+//
+// If the client was created with [WithRequireTls], and the connection is not
+// protected by TLS, the credentials are not sent and [ErrTlsRequired] is
+// returned.
 func (c *Client) Authenticate(user string, pass string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.ensureOpen("AUTHINFO"); err != nil {
+		return err
+	}
+	if c.requireTls && !c.isTls() {
+		return ErrTlsRequired
+	}
+
+	if err := c.requireCapability("AUTHINFO", "USER"); err != nil {
+		return err
+	}
+
 	code, message, err := c.sendCommand("AUTHINFO USER " + user)
 	if err != nil {
 		return err
@@ -19,6 +37,8 @@ func (c *Client) Authenticate(user string, pass string) error {
 	if code != 281 {
 		return AuthError(code, message)
 	}
+	c.state = StateAuthenticated
+	c.invalidateCapabilities()
 
 	return nil
 }
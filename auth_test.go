@@ -1,11 +1,14 @@
 package nntpclient
 
 import (
+	"crypto/tls"
 	"errors"
 	"net"
 	"testing"
 
+	"github.com/spf13/cast"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Authenticate(t *testing.T) {
@@ -93,5 +96,61 @@ func Test_Authenticate(t *testing.T) {
 
 		err := client.Authenticate("foo", "bar")
 		assert.Nil(t, err)
+		assert.Equal(t, StateAuthenticated, client.State())
+	})
+
+	t.Run("refuses plain text connections when tls is required", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			t.Error("credentials must not be sent")
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+		client, err := NewWithPort(server.Host, server.Port, WithRequireTls())
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		err = client.Authenticate("foo", "bar")
+		assert.Equal(t, true, errors.Is(err, ErrTlsRequired))
+		assert.Equal(t, StateConnected, client.State())
+	})
+
+	t.Run("allows tls connections when tls is required", func(t *testing.T) {
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+			Certificates: []tls.Certificate{fakeCert},
+		})
+		require.Nil(t, err)
+		defer listener.Close()
+		server := &TestServer{
+			listener: listener,
+			t:        t,
+			handler: func(t *testing.T, c net.Conn, cmd string, params []string) {
+				if params[0] == "USER" {
+					writeLines(c, "381 user accepted")
+					return
+				}
+				writeLines(c, "281 pass accepted")
+			},
+		}
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			writeLines(conn, "200 welcome")
+			server.router(conn)
+		}()
+
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		client, err := NewTls(host, WithRequireTls())
+		require.Nil(t, err)
+		client.port = cast.ToInt(port)
+		client.tlsConfig.InsecureSkipVerify = true
+		require.Nil(t, client.Connect())
+
+		err = client.Authenticate("foo", "bar")
+		assert.Nil(t, err)
+		assert.Equal(t, StateAuthenticated, client.State())
 	})
 }
@@ -19,6 +19,17 @@ func (c *Client) BodyAsBytes(id string) ([]byte, error) {
 // read from the connection it is written to writer. The id parameter is
 // handled in the same way as it is by [Article].
 func (c *Client) Body(id string, writer io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.body(id, writer)
+}
+
+func (c *Client) body(id string, writer io.Writer) error {
+	if err := c.requireCapability("READER"); err != nil {
+		return err
+	}
+
 	var cmd string
 	if id == "" {
 		cmd = "BODY"
@@ -45,6 +56,7 @@ func (c *Client) Body(id string, writer io.Writer) error {
 	if code != 222 {
 		return UnexpectedError(code, message)
 	}
+	c.trackArticle(id, message)
 
 	err = c.readBody(writer)
 	return err
@@ -3,17 +3,110 @@ package nntpclient
 import (
 	"bufio"
 	"bytes"
+	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/spf13/cast"
 )
 
 // Capabilities is a map of all capability labels to the label's possible
 // arguments. For example, the `COMPRESS` label may have a list of possible
-// arguments that looks like `[DEFLATE]`.
+// arguments that looks like `[DEFLATE]`. Labels are always upper case.
 type Capabilities map[string][]string
 
+// Has indicates if the given label is advertised. If any args are provided,
+// each one must also be advertised as an argument of the label. Arguments
+// are compared case-insensitively.
+func (caps Capabilities) Has(label string, args ...string) bool {
+	advertised, found := caps[strings.ToUpper(label)]
+	if !found {
+		return false
+	}
+	for _, arg := range args {
+		matches := func(a string) bool { return strings.EqualFold(a, arg) }
+		if !slices.ContainsFunc(advertised, matches) {
+			return false
+		}
+	}
+	return true
+}
+
+// Version returns the highest protocol version advertised by the server, or
+// `0` if no version is advertised.
+func (caps Capabilities) Version() int {
+	version := 0
+	for _, v := range caps["VERSION"] {
+		version = max(version, cast.ToInt(v))
+	}
+	return version
+}
+
+// HasReader indicates if the server supports the commands for reading
+// articles, e.g. `GROUP` and `ARTICLE`.
+func (caps Capabilities) HasReader() bool {
+	return caps.Has("READER")
+}
+
+// HasModeReader indicates if the server is a mode-switching server that
+// requires `MODE READER` prior to issuing reader commands.
+func (caps Capabilities) HasModeReader() bool {
+	return caps.Has("MODE-READER")
+}
+
+// ListVariants returns the keywords supported by the `LIST` command, e.g.
+// `ACTIVE` and `NEWSGROUPS`.
+func (caps Capabilities) ListVariants() []string {
+	return caps["LIST"]
+}
+
+// SupportsOver indicates if the server supports the `OVER` command.
+func (caps Capabilities) SupportsOver() bool {
+	return caps.Has("OVER")
+}
+
+// SaslMechanisms returns the SASL mechanisms supported by the `AUTHINFO SASL`
+// command.
+func (caps Capabilities) SaslMechanisms() []string {
+	return caps["SASL"]
+}
+
+// WithCapabilityChecks enables verifying that the server advertises support
+// for a command, via `CAPABILITIES`, prior to issuing the command. Commands
+// that are not advertised fail with [ErrNotSupported] without being sent
+// to the server. Servers that do not support `CAPABILITIES` at all are
+// assumed to support every command.
+func WithCapabilityChecks() Option {
+	return func(client *Client) {
+		client.capabilityChecks = true
+	}
+}
+
 // Capabilities gets a mapping of all supported labels to their possible
-// arguments.
+// arguments. The result is cached by the client until the capabilities may
+// have changed, i.e. after authenticating, after upgrading the connection
+// with STARTTLS, or after switching modes with `MODE READER`.
 func (c *Client) Capabilities() (*Capabilities, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	caps, err := c.capabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(Capabilities, len(caps))
+	for label, args := range caps {
+		result[label] = slices.Clone(args)
+	}
+	return &result, nil
+}
+
+func (c *Client) capabilities() (Capabilities, error) {
+	if c.cachedCapabilities != nil {
+		return c.cachedCapabilities, nil
+	}
+
 	code, message, err := c.sendCommand("CAPABILITIES")
 	if err != nil {
 		return nil, err
@@ -21,6 +114,7 @@ func (c *Client) Capabilities() (*Capabilities, error) {
 	c.logger.Debug("capabilities", "code", code, "message", message)
 
 	if code != 101 {
+		c.capabilitiesUnknown = true
 		return nil, UnexpectedError(code, message)
 	}
 
@@ -35,8 +129,42 @@ func (c *Client) Capabilities() (*Capabilities, error) {
 	for scanner.Scan() {
 		scanLine := scanner.Text()
 		parts := strings.Fields(scanLine)
-		capabilities[parts[0]] = parts[1:]
+		if len(parts) == 0 {
+			continue
+		}
+		capabilities[strings.ToUpper(parts[0])] = parts[1:]
 	}
 
-	return &capabilities, nil
+	c.cachedCapabilities = capabilities
+	return capabilities, nil
+}
+
+// invalidateCapabilities discards the cached capabilities. It must be invoked
+// whenever the server may change the capabilities it advertises.
+func (c *Client) invalidateCapabilities() {
+	c.cachedCapabilities = nil
+	c.capabilitiesUnknown = false
+}
+
+// requireCapability verifies that the server advertises the given label and
+// arguments when capability checks are enabled. See [WithCapabilityChecks].
+func (c *Client) requireCapability(label string, args ...string) error {
+	// When the server does not support `CAPABILITIES` we cannot know what
+	// is supported. So we let the server decide.
+	if !c.capabilityChecks || c.capabilitiesUnknown {
+		return nil
+	}
+
+	caps, err := c.capabilities()
+	if err != nil {
+		if c.capabilitiesUnknown {
+			return nil
+		}
+		return err
+	}
+
+	if !caps.Has(label, args...) {
+		return fmt.Errorf("%s: %w", strings.Join(append([]string{label}, args...), " "), ErrNotSupported)
+	}
+	return nil
 }
@@ -3,9 +3,11 @@ package nntpclient
 import (
 	"errors"
 	"net"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Capabilities(t *testing.T) {
@@ -75,3 +77,159 @@ func Test_Capabilities(t *testing.T) {
 		assert.Equal(t, expected, caps)
 	})
 }
+
+func Test_CapabilitiesAccessors(t *testing.T) {
+	caps := Capabilities{
+		"VERSION":     {"2", "3"},
+		"READER":      {},
+		"MODE-READER": {},
+		"LIST":        {"ACTIVE", "NEWSGROUPS"},
+		"OVER":        {"MSGID"},
+		"SASL":        {"PLAIN", "SCRAM-SHA-256"},
+		"AUTHINFO":    {"USER", "SASL"},
+	}
+
+	assert.Equal(t, 3, caps.Version())
+	assert.Equal(t, true, caps.HasReader())
+	assert.Equal(t, true, caps.HasModeReader())
+	assert.Equal(t, []string{"ACTIVE", "NEWSGROUPS"}, caps.ListVariants())
+	assert.Equal(t, true, caps.SupportsOver())
+	assert.Equal(t, []string{"PLAIN", "SCRAM-SHA-256"}, caps.SaslMechanisms())
+	assert.Equal(t, true, caps.Has("authinfo", "user"))
+	assert.Equal(t, true, caps.Has("LIST", "ACTIVE", "NEWSGROUPS"))
+	assert.Equal(t, false, caps.Has("LIST", "ACTIVE.TIMES"))
+	assert.Equal(t, false, caps.Has("STARTTLS"))
+
+	empty := Capabilities{}
+	assert.Equal(t, 0, empty.Version())
+	assert.Equal(t, false, empty.HasReader())
+	assert.Nil(t, empty.ListVariants())
+	assert.Nil(t, empty.SaslMechanisms())
+}
+
+func Test_CapabilitiesCaching(t *testing.T) {
+	countingHandler := func(requests *atomic.Int32) commandHandler {
+		return func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "capabilities":
+				requests.Add(1)
+				writeLines(c, "101 capabilities", "VERSION 2", "reader", "AUTHINFO USER", ".")
+			case "authinfo":
+				if params[0] == "USER" {
+					writeLines(c, "381 user accepted")
+					return
+				}
+				writeLines(c, "281 pass accepted")
+			case "mode":
+				writeLines(c, "200 posting allowed")
+			}
+		}
+	}
+
+	t.Run("caches results", func(t *testing.T) {
+		var requests atomic.Int32
+		server, client := getServerAndClient(t, countingHandler(&requests))
+		defer server.Close()
+
+		caps, err := client.Capabilities()
+		require.Nil(t, err)
+		assert.Equal(t, true, caps.HasReader())
+
+		// Modifying the result must not modify the cache.
+		delete(*caps, "READER")
+
+		caps, err = client.Capabilities()
+		require.Nil(t, err)
+		assert.Equal(t, true, caps.HasReader())
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("refreshes after authentication", func(t *testing.T) {
+		var requests atomic.Int32
+		server, client := getServerAndClient(t, countingHandler(&requests))
+		defer server.Close()
+
+		_, err := client.Capabilities()
+		require.Nil(t, err)
+		require.Nil(t, client.Authenticate("foo", "bar"))
+		_, err = client.Capabilities()
+		require.Nil(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("refreshes after mode reader", func(t *testing.T) {
+		var requests atomic.Int32
+		server, client := getServerAndClient(t, countingHandler(&requests))
+		defer server.Close()
+
+		_, err := client.Capabilities()
+		require.Nil(t, err)
+		require.Nil(t, client.ModeReader())
+		_, err = client.Capabilities()
+		require.Nil(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+}
+
+func Test_requireCapability(t *testing.T) {
+	t.Run("does nothing when checks are disabled", func(t *testing.T) {
+		client := Client{}
+		assert.Nil(t, client.requireCapability("OVER"))
+	})
+
+	t.Run("fails early for unsupported commands", func(t *testing.T) {
+		var groups atomic.Int32
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "capabilities":
+				writeLines(c, "101 capabilities", "VERSION 2", "LIST ACTIVE", ".")
+			case "group":
+				groups.Add(1)
+				writeLines(c, "211 1 1 1 foo")
+			}
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+		client, err := NewWithPort(server.Host, server.Port, WithCapabilityChecks())
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		summary, err := client.Group("foo")
+		assert.Nil(t, summary)
+		assert.Equal(t, true, errors.Is(err, ErrNotSupported))
+		assert.ErrorContains(t, err, "READER: not supported by server")
+		assert.Equal(t, int32(0), groups.Load())
+
+		_, err = client.ListActiveTimes("")
+		assert.ErrorContains(t, err, "LIST ACTIVE.TIMES: not supported by server")
+	})
+
+	t.Run("allows everything when capabilities are unavailable", func(t *testing.T) {
+		var capRequests atomic.Int32
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "capabilities":
+				capRequests.Add(1)
+				writeLines(c, "500 what?")
+			case "group":
+				writeLines(c, "211 1 1 1 foo")
+			}
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+		client, err := NewWithPort(server.Host, server.Port, WithCapabilityChecks())
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		for i := 0; i < 2; i++ {
+			summary, err := client.Group("foo")
+			assert.Nil(t, err)
+			assert.Equal(t, "foo", summary.Name)
+		}
+		assert.Equal(t, int32(1), capRequests.Load())
+	})
+}
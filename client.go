@@ -2,6 +2,7 @@ package nntpclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -12,6 +13,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cast"
 )
@@ -21,16 +24,51 @@ const lineTerminatorByte = 0x0a // "\n"
 // Client is a simple [NNTP](https://datatracker.ietf.org/doc/html/rfc3977)
 // client. Client instances should be created with [New], [NewTls], or
 // [NewWithPort] (this one being the most flexible).
+//
+// A Client is safe for concurrent use by multiple goroutines. Each command,
+// including the reading of any multi-line response, is performed while
+// holding an exclusive lock on the client. Consequently, an [io.Writer]
+// supplied to methods such as [Client.Article] must not invoke methods on
+// the same client.
 type Client struct {
+	mu sync.Mutex
+
 	host      string
 	port      int
-	dialer    *net.Dialer
+	dialer    ContextDialer
 	tlsConfig *tls.Config
 	conn      net.Conn
+	state     ConnState
+
+	clientCertificates []tls.Certificate
+	pinnedPublicKeys   [][]byte
+
+	dialTimeout     time.Duration
+	commandTimeout  time.Duration
+	readIdleTimeout time.Duration
+
+	keepaliveIdle    time.Duration
+	keepaliveErrFunc func(error)
+	keepaliveStop    chan struct{}
+	lastActivity     time.Time
 
 	logger *slog.Logger
 
 	currentResponse *Response
+	dataReader      *idleReader
+
+	currentGroup         *GroupSummary
+	currentArticleNumber int
+	currentArticleId     string
+
+	shortYearDates bool
+	charsetReader  CharsetReader
+
+	cachedCapabilities  Capabilities
+	capabilitiesUnknown bool
+	capabilityChecks    bool
+	negotiateModeReader bool
+	requireTls          bool
 
 	// CanPost indicates if the server will allow the client to post articles.
 	// Useful in the future when posting is supported by the client.
@@ -39,6 +77,47 @@ type Client struct {
 
 type Option func(client *Client)
 
+// ContextDialer is the interface of dialers that can be used to establish the
+// connection with the remote server. It is satisfied by [net.Dialer], and by
+// the proxy dialers provided by this package: [Socks5Dialer] and
+// [HttpConnectDialer].
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// ConnState describes where a [Client] is in its connection lifecycle. The
+// lifecycle only moves forward: a closed client cannot be reconnected, a new
+// instance must be created instead.
+type ConnState int
+
+const (
+	// StateNew is the state of a client that has not yet connected.
+	StateNew ConnState = iota
+	// StateConnected is the state of a client that has received a successful
+	// greeting from the server.
+	StateConnected
+	// StateAuthenticated is the state of a connected client that has
+	// successfully completed [Client.Authenticate].
+	StateAuthenticated
+	// StateClosed is the state of a client after [Client.Quit] or
+	// [Client.Close] has been invoked.
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateConnected:
+		return "connected"
+	case StateAuthenticated:
+		return "authenticated"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("ConnState(%d)", int(s))
+}
+
 // New creates a new [Client] instance that connects to the given `host`
 // according to the given options. Instances created with this method will
 // _always_ try to connect on port 119. Thus, it is not compatible with
@@ -72,6 +151,63 @@ func WithDialer(dialer *net.Dialer) Option {
 	}
 }
 
+// WithContextDialer is a more general form of [WithDialer] that accepts
+// any [ContextDialer], e.g. a proxy dialer. When a TLS configuration is
+// provided, the TLS handshake is performed over the connection returned
+// by the dialer.
+func WithContextDialer(dialer ContextDialer) Option {
+	return func(client *Client) {
+		client.dialer = dialer
+	}
+}
+
+// WithDialTimeout limits the time allowed for establishing the connection
+// with the remote server, including the TLS handshake when connecting to a
+// TLS enabled port. A zero duration, the default, means no limit beyond any
+// imposed by the dialer.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.dialTimeout = timeout
+	}
+}
+
+// WithCommandTimeout limits the time allowed for sending a command, or
+// reading the initial greeting, and receiving the initial response line.
+// Multi-line data blocks that follow the response line, e.g. an article body,
+// are governed by [WithReadIdleTimeout] instead, so that large responses are
+// not cut short. A zero duration, the default, means no limit.
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.commandTimeout = timeout
+	}
+}
+
+// WithReadIdleTimeout limits the time allowed between receiving bytes while
+// reading a multi-line data block, e.g. an article body. It allows detecting
+// a server that stops sending in the middle of a large response without
+// limiting the total time the response may take. A zero duration, the
+// default, means no limit.
+func WithReadIdleTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.readIdleTimeout = timeout
+	}
+}
+
+// WithKeepalive enables a background keepalive for the connection. Whenever
+// the connection has been idle for the given duration, a `DATE` command is
+// sent so that the server does not drop the session. Keepalive commands are
+// serialized with regular commands, and any command resets the idle period.
+//
+// If a keepalive command fails, the keepalive stops and onError, when not nil,
+// is invoked with the resulting error. This allows the owner of the client to
+// discard the connection, e.g. by invoking [Client.Close] from within onError.
+func WithKeepalive(idle time.Duration, onError func(error)) Option {
+	return func(client *Client) {
+		client.keepaliveIdle = idle
+		client.keepaliveErrFunc = onError
+	}
+}
+
 // WithLogger allows defining the logger instance that will be used when
 // logging messages. The default logger logs at the "info" level to the
 // stdout stream.
@@ -110,56 +246,170 @@ func _new(host string, port int, opts ...Option) (*Client, error) {
 	return client, nil
 }
 
+// State returns the current lifecycle state of the client.
+func (c *Client) State() ConnState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
 // Connect establishes a connection to the server. This method must be invoked
-// once prior to any command methods.
+// once prior to any command methods. Invoking it on a client that is already
+// connected, or that has been closed, results in a [*StateError].
 func (c *Client) Connect() error {
-	// TODO: add a c.connected property and check it here
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case StateConnected, StateAuthenticated:
+		return &StateError{Op: "connect", State: c.state, Err: ErrAlreadyConnected}
+	case StateClosed:
+		return &StateError{Op: "connect", State: c.state, Err: ErrClientClosed}
+	}
+
 	address := net.JoinHostPort(c.host, fmt.Sprint(c.port))
 
-	switch {
-	case c.tlsConfig == nil:
-		conn, err := c.dialer.Dial("tcp", address)
-		if err != nil {
-			return err
-		}
-		c.conn = conn
-	case c.tlsConfig != nil:
-		conn, err := tls.DialWithDialer(c.dialer, "tcp", address, c.tlsConfig)
-		if err != nil {
+	ctx := context.Background()
+	if c.dialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.dialTimeout)
+		defer cancel()
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if c.tlsConfig != nil {
+		tlsConn := tls.Client(conn, c.prepareTlsConfig(c.tlsConfig))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
 			return err
 		}
-		c.conn = conn
+		conn = tlsConn
 	}
+	c.conn = conn
 
+	if c.commandTimeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.commandTimeout))
+	}
 	c.currentResponse = NewResponse(c.conn)
 	code, _, err := c.readInitialResponse()
 	if err != nil {
+		// Leave the client in the "new" state so that connecting can
+		// be retried.
+		c.conn.Close()
+		c.conn = nil
 		return err
 	}
 
 	if code == 200 {
 		c.CanPost = true
 	}
+	c.state = StateConnected
+	c.lastActivity = time.Now()
+	c.invalidateCapabilities()
+
+	if c.negotiateModeReader {
+		if err := c.negotiateReaderMode(); err != nil {
+			c.conn.Close()
+			c.conn = nil
+			c.state = StateNew
+			return err
+		}
+	}
+
+	if c.keepaliveIdle > 0 {
+		c.keepaliveStop = make(chan struct{})
+		go c.keepalive(c.keepaliveStop)
+	}
 
 	return nil
 }
 
+// keepalive sends a `DATE` command whenever the connection has been idle for
+// the configured keepalive period. It runs until stop is closed, the client
+// is closed, or a keepalive command fails.
+func (c *Client) keepalive(stop <-chan struct{}) {
+	timer := time.NewTimer(c.keepaliveIdle)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+
+		c.mu.Lock()
+		if c.state == StateClosed {
+			c.mu.Unlock()
+			return
+		}
+		idle := time.Since(c.lastActivity)
+		if idle < c.keepaliveIdle {
+			c.mu.Unlock()
+			timer.Reset(c.keepaliveIdle - idle)
+			continue
+		}
+		_, err := c.date()
+		c.mu.Unlock()
+
+		if err != nil {
+			c.logger.Debug("keepalive failed", "error", err)
+			if c.keepaliveErrFunc != nil {
+				c.keepaliveErrFunc(err)
+			}
+			return
+		}
+		timer.Reset(c.keepaliveIdle)
+	}
+}
+
+// ensureOpen verifies that the client is in a state where commands can be
+// issued to the server.
+func (c *Client) ensureOpen(op string) error {
+	if c.state == StateClosed {
+		return &StateError{Op: op, State: c.state, Err: ErrClientClosed}
+	}
+	if c.conn == nil {
+		return &StateError{Op: op, State: c.state, Err: ErrNotConnected}
+	}
+	return nil
+}
+
 // sendCommand writes the provided command to the server, processes the
 // initial response line, and returns the information processed from that
 // line. If a command returns more data than a single response line, the
 // [readHeaders] and [readBody] methods should be used subsequent to this
 // method.
 func (c *Client) sendCommand(command string) (code int, message string, err error) {
+	op, _, _ := strings.Cut(command, " ")
+	if err = c.ensureOpen(op); err != nil {
+		return -1, "", err
+	}
+
+	// The deadline is always reset, as reading the data block of a previous
+	// command may have left a read deadline behind.
+	deadline := time.Time{}
+	if c.commandTimeout > 0 {
+		deadline = time.Now().Add(c.commandTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+
 	_, err = fmt.Fprintf(c.conn, "%s\r\n", command)
 	if err != nil {
-		return -1, "", err
+		return -1, "", c.checkTimeout(err)
 	}
 
-	c.currentResponse = NewResponse(c.conn)
+	c.lastActivity = time.Now()
+	c.dataReader = &idleReader{conn: c.conn}
+	c.currentResponse = NewResponse(c.dataReader)
 
 	line, err := c.readSingleLineResponse()
 	if err != nil {
-		return -1, "", err
+		return -1, "", c.checkTimeout(err)
 	}
 	code, err = strconv.Atoi(line[0:3])
 	if err != nil {
@@ -204,11 +454,38 @@ func (c *Client) readSingleLineResponse() (string, error) {
 	return string(readBytes), nil
 }
 
+// beginDataBlock switches the connection deadlines from those governing the
+// initial response line to those governing a multi-line data block.
+func (c *Client) beginDataBlock() {
+	if c.dataReader == nil {
+		return
+	}
+	if c.commandTimeout > 0 {
+		c.conn.SetDeadline(time.Time{})
+	}
+	c.dataReader.timeout = c.readIdleTimeout
+}
+
+// checkTimeout inspects an error returned while communicating with the server.
+// If the error is the result of an exceeded deadline, the state of the
+// protocol stream is unknown, so the connection is terminated and the client
+// is considered closed. The error is returned unchanged, i.e. it can be
+// checked with `errors.Is(err, os.ErrDeadlineExceeded)`.
+func (c *Client) checkTimeout(err error) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		c.logger.Debug("deadline exceeded, closing connection", "error", err)
+		c.conn.Close()
+		c.state = StateClosed
+	}
+	return err
+}
+
 // readHeaders is used to read a headers, or headers-like, block after issuing
 // a command.
-func (c *Client) readHeaders() (textproto.MIMEHeader, error) {
-	headers, _, err := ReadHeaders(c.currentResponse)
-	return headers, err
+func (c *Client) readHeaders() (HeaderBlock, error) {
+	c.beginDataBlock()
+	headers, _, err := ReadHeaderBlock(c.currentResponse)
+	return headers, c.checkTimeout(err)
 }
 
 // readBody is used to read a body, or body-like, block from a response after
@@ -216,7 +493,8 @@ func (c *Client) readHeaders() (textproto.MIMEHeader, error) {
 // passed in [io.Writer]. This allows for processing of bodies according to
 // their content by a client.
 func (c *Client) readBody(writer io.Writer) error {
-	return ReadBody(c.currentResponse, writer)
+	c.beginDataBlock()
+	return c.checkTimeout(ReadBody(c.currentResponse, writer))
 }
 
 // ReadHeaders parses a set of bytes with the expectation that they start
@@ -227,60 +505,18 @@ func (c *Client) readBody(writer io.Writer) error {
 // The result of this method, in the success case, is a map of headers and
 // an integer representing the offset of the last read byte, e.g. the start
 // of the body in an article. Otherwise, and error is returned and the other
-// values may be incomplete or incorrect.
+// values may be incomplete or incorrect. Malformed header lines result in a
+// [HeaderParseError]. See [ReadHeaderBlock] for retaining the original order
+// and case of the headers.
 //
 // If an end of file is reached before a header block termination line then
 // the [io.EOF] error will be returned along with the last read offset.
 func ReadHeaders(reader MultibyteReader) (textproto.MIMEHeader, int, error) {
-	offset := 0
-	result := make(textproto.MIMEHeader)
-
-	// lastHeader is the most recently found header. We need this if the header
-	// value has been folded.
-	lastHeader := ""
-	// endOfHeaders is the empty line between a header block and a body block.
-	endOfHeaders := []byte("\r\n")
-	// endOfResponse is the single dot that follows an informational command like "HEAD".
-	endOfResponse := []byte(".\r\n")
-	for {
-		readBytes, err := reader.ReadBytes(lineTerminatorByte)
-		offset += len(readBytes)
-
-		if err != nil {
-			if err == io.EOF {
-				return nil, offset, ErrUnexpectedEOF
-			}
-			return nil, offset, err
-		}
-
-		if bytes.Equal(readBytes, endOfHeaders) || bytes.Equal(readBytes, endOfResponse) {
-			break
-		}
-
-		leadingByte := readBytes[0]
-		if leadingByte == 0x20 || leadingByte == 0x09 {
-			// Line starts with a space character or a tab character.
-			// Therefore, it must be a folded value.
-			if lastHeader == "" {
-				return nil, offset, errors.New("malformed headers, found folded value without name")
-			}
-
-			values := result.Values(lastHeader)
-			lastValue := values[len(values)-1]
-			lastValue = lastValue + string(readBytes[:len(readBytes)-2])
-			values[len(values)-1] = lastValue
-			continue
-		}
-
-		colonIndex := bytes.IndexByte(readBytes, 0x3a)
-		name := string(readBytes[0:colonIndex])
-		// Omit the leading ": ", and trailing "\r\n"
-		value := string(readBytes[colonIndex+2 : len(readBytes)-2])
-		result.Add(name, value)
-		lastHeader = name
+	block, offset, err := ReadHeaderBlock(reader)
+	if err != nil {
+		return nil, offset, err
 	}
-
-	return result, offset, nil
+	return block.MIMEHeader(), offset, nil
 }
 
 // ReadBody is used to read a body, or body-like, block of bytes provided
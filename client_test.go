@@ -10,6 +10,10 @@ import (
 	"log/slog"
 	"net"
 	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -45,6 +49,13 @@ func Test__new(t *testing.T) {
 		assert.Equal(t, false, c.logger.Enabled(context.TODO(), slog.LevelDebug))
 	})
 
+	t.Run("accepts any context dialer", func(t *testing.T) {
+		dialer := &Socks5Dialer{ProxyAddress: "127.0.0.1:1080"}
+		c, err := _new("127.0.0.1", 119, WithContextDialer(dialer))
+		assert.Nil(t, err)
+		assert.Equal(t, dialer, c.dialer)
+	})
+
 	t.Run("processes options", func(t *testing.T) {
 		dialer := &net.Dialer{}
 		tlsConfig := &tls.Config{}
@@ -59,10 +70,16 @@ func Test__new(t *testing.T) {
 			WithDialer(dialer),
 			WithLogger(logger),
 			WithTlsConfig(tlsConfig),
+			WithDialTimeout(time.Second),
+			WithCommandTimeout(2*time.Second),
+			WithReadIdleTimeout(3*time.Second),
 		)
 		assert.Nil(t, err)
 		assert.Equal(t, dialer, c.dialer)
 		assert.Equal(t, tlsConfig, tlsConfig)
+		assert.Equal(t, time.Second, c.dialTimeout)
+		assert.Equal(t, 2*time.Second, c.commandTimeout)
+		assert.Equal(t, 3*time.Second, c.readIdleTimeout)
 
 		c.logger.Info("test")
 		assert.Contains(t, logs.String(), `"msg":"test"`)
@@ -137,10 +154,72 @@ func Test_Connect(t *testing.T) {
 		err = c.Connect()
 		assert.Nil(t, err)
 		assert.Equal(t, true, c.CanPost)
+		assert.Equal(t, StateConnected, c.State())
+	})
+
+	t.Run("leaves client new after a failed connection", func(t *testing.T) {
+		c := Client{
+			host:   "127.0.0.1",
+			port:   -1,
+			dialer: &net.Dialer{},
+		}
+		err := c.Connect()
+		assert.Error(t, err)
+		assert.Equal(t, StateNew, c.State())
+		assert.Nil(t, c.conn)
+	})
+
+	t.Run("rejects connecting twice", func(t *testing.T) {
+		server, client := getServerAndClient(t, func(*testing.T, net.Conn, string, []string) {})
+		defer server.Close()
+
+		err := client.Connect()
+		assert.Equal(t, true, errors.Is(err, ErrAlreadyConnected))
+
+		var stateErr *StateError
+		require.Equal(t, true, errors.As(err, &stateErr))
+		assert.Equal(t, "connect", stateErr.Op)
+		assert.Equal(t, StateConnected, stateErr.State)
+	})
+
+	t.Run("rejects connecting a closed client", func(t *testing.T) {
+		c := Client{state: StateClosed}
+		err := c.Connect()
+		assert.Equal(t, true, errors.Is(err, ErrClientClosed))
 	})
 }
 
+func Test_ConnState(t *testing.T) {
+	assert.Equal(t, "new", StateNew.String())
+	assert.Equal(t, "connected", StateConnected.String())
+	assert.Equal(t, "authenticated", StateAuthenticated.String())
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "ConnState(42)", ConnState(42).String())
+}
+
 func Test_sendCommand(t *testing.T) {
+	t.Run("returns error when not connected", func(t *testing.T) {
+		c := Client{}
+
+		code, message, err := c.sendCommand("DATE")
+		assert.Equal(t, -1, code)
+		assert.Equal(t, "", message)
+		assert.Equal(t, true, errors.Is(err, ErrNotConnected))
+		assert.ErrorContains(t, err, "DATE: client is not connected (state: new)")
+	})
+
+	t.Run("returns error when closed", func(t *testing.T) {
+		c := Client{
+			conn:  responseConn{response: &singleLineReader{line: "200 ok\r\n"}},
+			state: StateClosed,
+		}
+
+		code, message, err := c.sendCommand("DATE")
+		assert.Equal(t, -1, code)
+		assert.Equal(t, "", message)
+		assert.Equal(t, true, errors.Is(err, ErrClientClosed))
+	})
+
 	t.Run("handles write error", func(t *testing.T) {
 		c := Client{
 			conn: &errWriterConn{},
@@ -298,7 +377,7 @@ func Test_readHeaders(t *testing.T) {
 			"Bar": {"bar"},
 			"Baz": {"baz"},
 		}
-		assert.Equal(t, expected, headers)
+		assert.Equal(t, expected, headers.MIMEHeader())
 	})
 
 	t.Run("reads unfolded dot terminated headers", func(t *testing.T) {
@@ -324,7 +403,7 @@ func Test_readHeaders(t *testing.T) {
 			"Bar": {"bar"},
 			"Baz": {"baz"},
 		}
-		assert.Equal(t, expected, headers)
+		assert.Equal(t, expected, headers.MIMEHeader())
 	})
 
 	t.Run("reads folded", func(t *testing.T) {
@@ -353,12 +432,12 @@ func Test_readHeaders(t *testing.T) {
 			"Bar": {"bar;\tbar"},
 			"Baz": {"baz"},
 		}
-		assert.Equal(t, expected, headers)
+		assert.Equal(t, expected, headers.MIMEHeader())
 	})
 
 	t.Run("returns error for unexpted folded header", func(t *testing.T) {
 		reader := &multiLineReader{
-			lines: []string{"\tfoo: foo"},
+			lines: []string{"\tfoo: foo", ""},
 		}
 		res := &Response{
 			bufferedReader: bufio.NewReader(reader),
@@ -416,3 +495,314 @@ func Test_readBody(t *testing.T) {
 		assert.Equal(t, "success\r\n", body.String())
 	})
 }
+
+func Test_ConcurrentCommands(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		switch cmd {
+		case "date":
+			writeLines(c, "111 20231112130000")
+		case "group":
+			writeLines(c, "211 3 1 3 "+params[0])
+		case "body":
+			writeLines(c, "222 0 "+params[0], "body of", params[0], ".")
+		case "quit":
+			writeLines(c, "205 bye")
+		}
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "group." + cast.ToString(i)
+			id := "<" + cast.ToString(i) + "@example.com>"
+
+			for j := 0; j < 25; j++ {
+				summary, err := client.Group(name)
+				if assert.Nil(t, err) {
+					assert.Equal(t, name, summary.Name)
+				}
+
+				body, err := client.BodyAsBytes(id)
+				if assert.Nil(t, err) {
+					assert.Equal(t, "body of\r\n"+id+"\r\n", string(body))
+				}
+
+				_, err = client.Date()
+				assert.Nil(t, err)
+				assert.Equal(t, StateConnected, client.State())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Closing while other goroutines issue commands must not corrupt the
+	// stream or panic; commands after the close must report the closed state.
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.Nil(t, client.Close())
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := client.Date()
+		if err != nil {
+			assert.Equal(t, true, errors.Is(err, ErrClientClosed))
+		}
+	}()
+	wg.Wait()
+
+	_, err := client.Group("after.close")
+	assert.Equal(t, true, errors.Is(err, ErrClientClosed))
+}
+
+func Test_Timeouts(t *testing.T) {
+	connect := func(t *testing.T, handler commandHandler, opts ...Option) (*TestServer, *Client) {
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+
+		client, err := NewWithPort(server.Host, server.Port, append(opts, WithLogger(NilLogger))...)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		return server, client
+	}
+
+	t.Run("command timeout detects unresponsive server", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {}
+		server, client := connect(t, handler, WithCommandTimeout(50*time.Millisecond))
+		defer server.Close()
+
+		_, err := client.Date()
+		assert.Equal(t, true, errors.Is(err, os.ErrDeadlineExceeded))
+		assert.Equal(t, StateClosed, client.State())
+	})
+
+	t.Run("command timeout does not limit data blocks", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "222 0 <a@b>")
+			for i := 0; i < 5; i++ {
+				time.Sleep(30 * time.Millisecond)
+				writeLines(c, "line")
+			}
+			writeLines(c, ".")
+		}
+		server, client := connect(t, handler, WithCommandTimeout(60*time.Millisecond))
+		defer server.Close()
+
+		body, err := client.BodyAsBytes("<a@b>")
+		assert.Nil(t, err)
+		assert.Equal(t, strings.Repeat("line\r\n", 5), string(body))
+	})
+
+	t.Run("read idle timeout detects stalled body", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "222 0 <a@b>", "partial")
+		}
+		server, client := connect(t, handler, WithReadIdleTimeout(50*time.Millisecond))
+		defer server.Close()
+
+		body, err := client.BodyAsBytes("<a@b>")
+		assert.Equal(t, true, errors.Is(err, os.ErrDeadlineExceeded))
+		assert.Equal(t, "partial\r\n", string(body))
+		assert.Equal(t, StateClosed, client.State())
+	})
+
+	t.Run("read idle timeout allows slow but steady body", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "222 0 <a@b>")
+			for i := 0; i < 6; i++ {
+				time.Sleep(30 * time.Millisecond)
+				writeLines(c, "line")
+			}
+			writeLines(c, ".")
+		}
+		server, client := connect(t, handler, WithReadIdleTimeout(150*time.Millisecond))
+		defer server.Close()
+
+		body, err := client.BodyAsBytes("<a@b>")
+		assert.Nil(t, err)
+		assert.Equal(t, strings.Repeat("line\r\n", 6), string(body))
+		assert.Equal(t, StateConnected, client.State())
+	})
+
+	t.Run("read idle timeout does not outlast the data block", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "help":
+				writeLines(c, "100 help follows")
+				time.Sleep(10 * time.Millisecond)
+				writeLines(c, "some help", ".")
+			case "date":
+				writeLines(c, "111 20231112130000")
+			}
+		}
+		server, client := connect(t, handler, WithReadIdleTimeout(50*time.Millisecond))
+		defer server.Close()
+
+		_, err := client.Help()
+		require.Nil(t, err)
+
+		time.Sleep(150 * time.Millisecond)
+		_, err = client.Date()
+		assert.Nil(t, err)
+		assert.Equal(t, StateConnected, client.State())
+	})
+
+	t.Run("dial timeout limits the tls handshake", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer listener.Close()
+		go func() {
+			// Accept, but never complete a handshake.
+			conn, _ := listener.Accept()
+			if conn != nil {
+				defer conn.Close()
+				time.Sleep(time.Second)
+			}
+		}()
+
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		c, err := NewWithPort(
+			host,
+			cast.ToInt(port),
+			WithTlsConfig(&tls.Config{InsecureSkipVerify: true}),
+			WithDialTimeout(50*time.Millisecond),
+		)
+		require.Nil(t, err)
+
+		start := time.Now()
+		err = c.Connect()
+		assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, StateNew, c.State())
+	})
+}
+
+func Test_Keepalive(t *testing.T) {
+	connect := func(t *testing.T, handler commandHandler, opts ...Option) (*TestServer, *Client) {
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+
+		client, err := NewWithPort(server.Host, server.Port, append(opts, WithLogger(NilLogger))...)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		return server, client
+	}
+
+	countingHandler := func(dates *atomic.Int32) commandHandler {
+		return func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "date":
+				dates.Add(1)
+				writeLines(c, "111 20231112130000")
+			case "group":
+				writeLines(c, "211 3 1 3 "+params[0])
+			case "quit":
+				writeLines(c, "205 bye")
+			}
+		}
+	}
+
+	t.Run("pings an idle connection", func(t *testing.T) {
+		var dates atomic.Int32
+		server, client := connect(t, countingHandler(&dates), WithKeepalive(20*time.Millisecond, nil))
+		defer server.Close()
+
+		assert.Eventually(t, func() bool {
+			return dates.Load() >= 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Nil(t, client.Close())
+	})
+
+	t.Run("does not ping while commands are issued", func(t *testing.T) {
+		var dates atomic.Int32
+		server, client := connect(t, countingHandler(&dates), WithKeepalive(100*time.Millisecond, nil))
+		defer server.Close()
+
+		for i := 0; i < 10; i++ {
+			_, err := client.Group("foo")
+			require.Nil(t, err)
+			time.Sleep(20 * time.Millisecond)
+		}
+		assert.Equal(t, int32(0), dates.Load())
+		assert.Nil(t, client.Close())
+	})
+
+	t.Run("stops pinging once closed", func(t *testing.T) {
+		var dates atomic.Int32
+		server, client := connect(t, countingHandler(&dates), WithKeepalive(20*time.Millisecond, nil))
+		defer server.Close()
+
+		assert.Nil(t, client.Close())
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(0), dates.Load())
+	})
+
+	t.Run("pings after a data block with read idle timeout", func(t *testing.T) {
+		var dates atomic.Int32
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "help":
+				writeLines(c, "100 help follows")
+				time.Sleep(10 * time.Millisecond)
+				writeLines(c, "some help", ".")
+			case "date":
+				dates.Add(1)
+				writeLines(c, "111 20231112130000")
+			case "quit":
+				writeLines(c, "205 bye")
+			}
+		}
+
+		failures := make(chan error, 1)
+		server, client := connect(
+			t,
+			handler,
+			WithReadIdleTimeout(100*time.Millisecond),
+			WithKeepalive(200*time.Millisecond, func(err error) {
+				failures <- err
+			}),
+		)
+		defer server.Close()
+
+		_, err := client.Help()
+		require.Nil(t, err)
+
+		assert.Eventually(t, func() bool {
+			return dates.Load() >= 2
+		}, 2*time.Second, 20*time.Millisecond)
+		select {
+		case err := <-failures:
+			t.Fatalf("keepalive failed: %v", err)
+		default:
+		}
+		assert.Equal(t, StateConnected, client.State())
+		assert.Nil(t, client.Close())
+	})
+
+	t.Run("reports failures", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "500 boom")
+		}
+
+		failures := make(chan error, 1)
+		server, client := connect(t, handler, WithKeepalive(20*time.Millisecond, func(err error) {
+			failures <- err
+		}))
+		defer server.Close()
+
+		select {
+		case err := <-failures:
+			assert.ErrorContains(t, err, "unexpected response code: 500 (boom)")
+		case <-time.After(time.Second):
+			t.Fatal("keepalive failure was not reported")
+		}
+		assert.Nil(t, client.Close())
+	})
+}
@@ -8,6 +8,13 @@ import (
 // Date retrieves the current date and time as it is known by the remote
 // server.
 func (c *Client) Date() (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.date()
+}
+
+func (c *Client) date() (time.Time, error) {
 	code, message, err := c.sendCommand("DATE")
 	if err != nil {
 		return time.Time{}, err
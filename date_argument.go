@@ -0,0 +1,37 @@
+package nntpclient
+
+import "time"
+
+// DateArgument formats the date and time arguments of the `NEWGROUPS` and
+// `NEWNEWS` commands. The time is always converted to, and sent as, GMT, so
+// that it does not depend on the time zone of the server. See RFC 3977 §7.3.
+type DateArgument struct {
+	Time time.Time
+	// ShortYear sends the year with two digits, as required by servers that
+	// only implement RFC 977. Such servers interpret the year as the closest
+	// year ending in those digits.
+	ShortYear bool
+}
+
+// String returns the arguments, e.g. `20231112 130000 GMT`.
+func (d DateArgument) String() string {
+	layout := "20060102 150405 GMT"
+	if d.ShortYear {
+		layout = "060102 150405 GMT"
+	}
+	return d.Time.UTC().Format(layout)
+}
+
+// WithShortYearDates makes [Client.NewGroups] and [Client.NewNews] send years
+// with two digits, see [DateArgument].
+func WithShortYearDates() Option {
+	return func(client *Client) {
+		client.shortYearDates = true
+	}
+}
+
+// dateArgument returns the date arguments for the time as configured for
+// the client.
+func (c *Client) dateArgument(t time.Time) string {
+	return DateArgument{Time: t, ShortYear: c.shortYearDates}.String()
+}
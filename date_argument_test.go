@@ -0,0 +1,40 @@
+package nntpclient
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DateArgument(t *testing.T) {
+	// 2023-11-12T08:00:00.000-05:00
+	local := time.Unix(1699794000, 0).In(time.FixedZone("EST", -5*60*60))
+
+	assert.Equal(t, "20231112 130000 GMT", DateArgument{Time: local}.String())
+	assert.Equal(t, "20231112 130000 GMT", DateArgument{Time: local.UTC()}.String())
+	assert.Equal(t, "231112 130000 GMT", DateArgument{Time: local, ShortYear: true}.String())
+
+	late := time.Date(1999, 12, 31, 23, 30, 0, 0, time.FixedZone("", -60*60))
+	assert.Equal(t, "000101 003000 GMT", DateArgument{Time: late, ShortYear: true}.String())
+}
+
+func Test_WithShortYearDates(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		assert.Equal(t, []string{"231112", "130000", "GMT"}, params)
+		writeLines(c, "231 groups", ".")
+	}
+
+	server, err := NewTestServer(t, handler)
+	require.Nil(t, err)
+	defer server.Close()
+	client, err := NewWithPort(server.Host, server.Port, WithShortYearDates())
+	require.Nil(t, err)
+	require.Nil(t, client.Connect())
+
+	list, err := client.NewGroups(time.Unix(1699794000, 0))
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+}
@@ -23,6 +23,7 @@ var ErrNoSuchGroup = fmt.Errorf("no such newsgroup found: %w", NntpError)
 /** Library specific errors that are still NNTP derived. */
 
 var ErrUnexpectedEOF = fmt.Errorf("unexpected end of response: %w", NntpError)
+var ErrNotSupported = fmt.Errorf("not supported by server: %w", NntpError)
 
 func AuthError(code int, message string) error {
 	return fmt.Errorf("auth failed with code: %d (%s): %w", code, message, NntpError)
@@ -31,3 +32,39 @@ func AuthError(code int, message string) error {
 func UnexpectedError(code int, message string) error {
 	return fmt.Errorf("unexpected response code: %d (%s): %w", code, message, NntpError)
 }
+
+// ErrProxy is the base error for failures reported by a proxy server while
+// establishing a connection through [Socks5Dialer] or [HttpConnectDialer].
+var ErrProxy = errors.New("proxy failure")
+
+// ErrCertificatePinMismatch is returned when the public key of the server
+// certificate does not match any of the pins provided to
+// [WithPinnedPublicKeys].
+var ErrCertificatePinMismatch = errors.New("server public key does not match pins")
+
+/** Library specific errors that stem from misuse of a client. */
+
+var ErrNotConnected = errors.New("client is not connected")
+var ErrAlreadyConnected = errors.New("client is already connected")
+var ErrClientClosed = errors.New("client is closed")
+var ErrAlreadyAuthenticated = errors.New("client is already authenticated")
+var ErrTlsActive = errors.New("connection is already protected by tls")
+var ErrTlsRequired = errors.New("refusing to send credentials without tls")
+
+// StateError is returned when an operation is not valid for the current
+// [ConnState] of a [Client]. The underlying error is one of [ErrNotConnected],
+// [ErrAlreadyConnected], [ErrClientClosed], or [ErrAlreadyAuthenticated], and
+// can be checked with [errors.Is].
+type StateError struct {
+	Op    string
+	State ConnState
+	Err   error
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("%s: %v (state: %s)", e.Op, e.Err, e.State)
+}
+
+func (e *StateError) Unwrap() error {
+	return e.Err
+}
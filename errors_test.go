@@ -17,6 +17,7 @@ func Test_SpecificErrorsWrapBase(t *testing.T) {
 	assert.Equal(t, true, errors.Is(ErrNoPrevArticle, NntpError))
 	assert.Equal(t, true, errors.Is(ErrReadingUnavailable, NntpError))
 	assert.Equal(t, true, errors.Is(ErrNoSuchGroup, NntpError))
+	assert.Equal(t, true, errors.Is(ErrNotSupported, NntpError))
 }
 
 func Test_AuthError(t *testing.T) {
@@ -29,3 +30,10 @@ func Test_UnexpectedError(t *testing.T) {
 	err := UnexpectedError(111, "foo")
 	assert.Equal(t, true, errors.Is(err, NntpError))
 }
+
+func Test_StateError(t *testing.T) {
+	err := &StateError{Op: "GROUP", State: StateClosed, Err: ErrClientClosed}
+	assert.Equal(t, "GROUP: client is closed (state: closed)", err.Error())
+	assert.Equal(t, true, errors.Is(err, ErrClientClosed))
+	assert.Equal(t, false, errors.Is(err, NntpError))
+}
@@ -1,11 +1,9 @@
 package nntpclient
 
 import (
-	"bufio"
-	"bytes"
+	"fmt"
+	"strconv"
 	"strings"
-
-	"github.com/spf13/cast"
 )
 
 // GroupSummary represents the details about a group.
@@ -23,8 +21,85 @@ type GroupList struct {
 	ArticleNumbers []int
 }
 
+// CurrentGroup returns the summary of the currently selected group as it was
+// reported when the group was selected by [Client.Group] or
+// [Client.ListGroup]. If no group has been selected, nil is returned.
+func (c *Client) CurrentGroup() *GroupSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.currentGroup == nil {
+		return nil
+	}
+	summary := *c.currentGroup
+	return &summary
+}
+
+// CurrentArticle returns the article number and message-id of the currently
+// selected article in the currently selected group. The number is `0` if
+// there is no current article. The message-id is the empty string if it is
+// not known, e.g. right after selecting a group.
+func (c *Client) CurrentArticle() (int, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.currentArticleNumber, c.currentArticleId
+}
+
+// selectGroup records the newly selected group. Per RFC 3977 §6.1.1, the
+// current article becomes the first article in the group, if there is one.
+func (c *Client) selectGroup(summary GroupSummary) {
+	c.currentGroup = &summary
+	c.currentArticleNumber = 0
+	c.currentArticleId = ""
+	if summary.Number > 0 {
+		c.currentArticleNumber = summary.Low
+	}
+}
+
+// parseArticleResponse parses the `n message-id` portion of a response line
+// that identifies an article, e.g. the response to `STAT`.
+func parseArticleResponse(message string) (int, string, error) {
+	parts := strings.Fields(message)
+	if len(parts) < 2 {
+		return -1, "", fmt.Errorf("could not process article response: %q", message)
+	}
+	number, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return -1, "", fmt.Errorf("could not process article number: %v", err)
+	}
+	return number, parts[1], nil
+}
+
+// trackArticle updates the current article from the response line to a
+// command that selects an article. Requesting an article by message-id does
+// not change the current article (RFC 3977 §6.2), so such requests are
+// ignored, as are response lines that cannot be parsed.
+func (c *Client) trackArticle(id string, message string) {
+	if strings.HasPrefix(id, "<") {
+		return
+	}
+	number, messageId, err := parseArticleResponse(message)
+	if err != nil {
+		return
+	}
+	c.currentArticleNumber = number
+	c.currentArticleId = messageId
+}
+
 // Group selects a group and returns the summary for that group.
 func (c *Client) Group(name string) (*GroupSummary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.group(name)
+}
+
+func (c *Client) group(name string) (*GroupSummary, error) {
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
+	}
+
 	code, message, err := c.sendCommand("GROUP " + name)
 	if err != nil {
 		return nil, err
@@ -36,21 +111,46 @@ func (c *Client) Group(name string) (*GroupSummary, error) {
 		return nil, UnexpectedError(code, message)
 	}
 
-	parts := strings.Fields(message)
-	result := &GroupSummary{
-		Name:   parts[3],
-		Number: cast.ToInt(parts[0]),
-		Low:    cast.ToInt(parts[1]),
-		High:   cast.ToInt(parts[2]),
+	summary, err := parseGroupResponse(message)
+	if err != nil {
+		return nil, err
 	}
+	c.selectGroup(summary)
 
-	return result, nil
+	return &summary, nil
 }
 
 // ListGroup selects a group and returns a summary for the group along with
-// a list of the group local article identifiers.
+// a list of the group local article identifiers. Malformed lines are skipped,
+// and reported by a [ParseErrors] returned along with the other identifiers.
 func (c *Client) ListGroup(name string) (*GroupList, error) {
-	code, message, err := c.sendCommand("LISTGROUP " + name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.listGroup("LISTGROUP " + name)
+}
+
+// ListGroupRange is like [Client.ListGroup], but only lists the identifiers
+// of the articles numbered from low to high, inclusive. If high is negative,
+// all articles numbered low or higher are listed. The summary still describes
+// the whole group.
+func (c *Client) ListGroupRange(name string, low int, high int) (*GroupList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	articleRange := fmt.Sprintf("%d-", low)
+	if high >= 0 {
+		articleRange += strconv.Itoa(high)
+	}
+	return c.listGroup(fmt.Sprintf("LISTGROUP %s %s", name, articleRange))
+}
+
+func (c *Client) listGroup(cmd string) (*GroupList, error) {
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
+	}
+
+	code, message, err := c.sendCommand(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -64,27 +164,46 @@ func (c *Client) ListGroup(name string) (*GroupList, error) {
 		return nil, UnexpectedError(code, message)
 	}
 
-	parts := strings.Fields(message)
-	result := &GroupList{
-		GroupSummary: GroupSummary{
-			Name:   parts[3],
-			Number: cast.ToInt(parts[0]),
-			Low:    cast.ToInt(parts[1]),
-			High:   cast.ToInt(parts[2]),
-		},
-		ArticleNumbers: make([]int, 0),
-	}
-
-	var body bytes.Buffer
-	err = c.readBody(&body)
+	summary, err := parseGroupResponse(message)
 	if err != nil {
+		// The list still follows, and is discarded to keep the connection
+		// usable.
+		if readErr := c.readLines(func(string) error { return nil }); readErr != nil {
+			return nil, readErr
+		}
+		return nil, err
+	}
+	c.selectGroup(summary)
+
+	result := &GroupList{GroupSummary: summary, ArticleNumbers: make([]int, 0)}
+	err = readParsed(c, parseArticleNumber, func(number int) error {
+		result.ArticleNumbers = append(result.ArticleNumbers, number)
+		return nil
+	})
+	if err != nil && !isParseErrors(err) {
 		return nil, err
 	}
-	scanner := bufio.NewScanner(bytes.NewReader(body.Bytes()))
-	for scanner.Scan() {
-		articleId := scanner.Text()
-		result.ArticleNumbers = append(result.ArticleNumbers, cast.ToInt(articleId))
+	return result, err
+}
+
+// parseGroupResponse parses the `number low high group` portion of the
+// response line to `GROUP` and `LISTGROUP`.
+func parseGroupResponse(message string) (GroupSummary, error) {
+	parts := strings.Fields(message)
+	if len(parts) < 4 {
+		return GroupSummary{}, fmt.Errorf("could not process group response: %q", message)
+	}
+	var numbers [3]int
+	for i, name := range []string{"article count", "low water mark", "high water mark"} {
+		number, err := parseNumber(parts[i], name)
+		if err != nil {
+			return GroupSummary{}, fmt.Errorf("could not process group response: %v", err)
+		}
+		numbers[i] = number
 	}
+	return GroupSummary{Name: parts[3], Number: numbers[0], Low: numbers[1], High: numbers[2]}, nil
+}
 
-	return result, nil
+func parseArticleNumber(line string) (int, error) {
+	return parseNumber(strings.TrimSpace(line), "article number")
 }
@@ -0,0 +1,84 @@
+package nntpclient
+
+import "strings"
+
+// GroupStatus is the status flag of a group as reported by `LIST ACTIVE`,
+// `NEWGROUPS`, and `LIST COUNTS`. See RFC 3977 §7.6.3 and RFC 6048 §2.2
+// for the meaning of the flags. Servers may report other flags, which are
+// retained unchanged.
+type GroupStatus string
+
+const (
+	// StatusPostingPermitted indicates that posting to the group is permitted.
+	StatusPostingPermitted GroupStatus = "y"
+	// StatusPostingProhibited indicates that posting to the group is not
+	// permitted.
+	StatusPostingProhibited GroupStatus = "n"
+	// StatusModerated indicates that postings to the group are sent to its
+	// moderator for approval.
+	StatusModerated GroupStatus = "m"
+	// StatusJunk indicates that articles posted to the group are only kept
+	// locally, if at all, and are not passed on to other servers.
+	StatusJunk GroupStatus = "j"
+	// StatusRemoved indicates that the group has been removed, and that
+	// articles posted to it are rejected.
+	StatusRemoved GroupStatus = "x"
+)
+
+// PostingPermitted indicates that posting to the group is permitted.
+func (s GroupStatus) PostingPermitted() bool {
+	return s == StatusPostingPermitted
+}
+
+// PostingProhibited indicates that posting to the group is not permitted.
+func (s GroupStatus) PostingProhibited() bool {
+	return s == StatusPostingProhibited
+}
+
+// IsModerated indicates that postings to the group are sent to its
+// moderator.
+func (s GroupStatus) IsModerated() bool {
+	return s == StatusModerated
+}
+
+// IsJunk indicates that articles posted to the group are not passed on.
+func (s GroupStatus) IsJunk() bool {
+	return s == StatusJunk
+}
+
+// IsRemoved indicates that the group has been removed.
+func (s GroupStatus) IsRemoved() bool {
+	return s == StatusRemoved
+}
+
+// IsAlias indicates that the group is an alias of another group, see
+// [GroupStatus.AliasOf].
+func (s GroupStatus) IsAlias() bool {
+	_, found := s.AliasOf()
+	return found
+}
+
+// AliasOf returns the name of the group that the group is an alias of, e.g.
+// `comp.lang.go` for the status `=comp.lang.go`. Articles posted to an
+// aliased group are filed in that group instead. The second result is false
+// if the group is not an alias.
+func (s GroupStatus) AliasOf() (string, bool) {
+	target, found := strings.CutPrefix(string(s), "=")
+	if !found || target == "" {
+		return "", false
+	}
+	return target, true
+}
+
+// CanPost indicates that articles may be posted to the group, either
+// directly or, for moderated groups, by way of the moderator. Posting to
+// aliased groups should use the group returned by [GroupStatus.AliasOf]
+// instead.
+func (s GroupStatus) CanPost() bool {
+	return s == StatusPostingPermitted || s == StatusModerated
+}
+
+// String returns the flag as reported by the server.
+func (s GroupStatus) String() string {
+	return string(s)
+}
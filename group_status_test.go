@@ -0,0 +1,46 @@
+package nntpclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GroupStatus(t *testing.T) {
+	tests := []struct {
+		status     GroupStatus
+		permitted  bool
+		prohibited bool
+		moderated  bool
+		junk       bool
+		removed    bool
+		canPost    bool
+		alias      string
+	}{
+		{status: "y", permitted: true, canPost: true},
+		{status: "n", prohibited: true},
+		{status: "m", moderated: true, canPost: true},
+		{status: "j", junk: true},
+		{status: "x", removed: true},
+		{status: "=comp.lang.go", alias: "comp.lang.go"},
+		{status: "="},
+		{status: "Y"},
+		{status: ""},
+	}
+
+	for _, test := range tests {
+		s := test.status
+		assert.Equal(t, test.permitted, s.PostingPermitted(), s)
+		assert.Equal(t, test.prohibited, s.PostingProhibited(), s)
+		assert.Equal(t, test.moderated, s.IsModerated(), s)
+		assert.Equal(t, test.junk, s.IsJunk(), s)
+		assert.Equal(t, test.removed, s.IsRemoved(), s)
+		assert.Equal(t, test.canPost, s.CanPost(), s)
+		assert.Equal(t, test.alias != "", s.IsAlias(), s)
+
+		alias, found := s.AliasOf()
+		assert.Equal(t, test.alias, alias, s)
+		assert.Equal(t, test.alias != "", found, s)
+		assert.Equal(t, string(s), s.String())
+	}
+}
@@ -2,9 +2,11 @@ package nntpclient
 
 import (
 	"errors"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Group(t *testing.T) {
@@ -39,6 +41,19 @@ func Test_Group(t *testing.T) {
 		assert.ErrorContains(t, err, "unexpected response code: 404 (missing)")
 	})
 
+	t.Run("handles malformed summary", func(t *testing.T) {
+		for _, line := range []string{"211 1 2 3\r\n", "211 1 x 3 foo\r\n"} {
+			c := Client{
+				conn: responseConn{response: &singleLineReader{line: line}},
+			}
+
+			summary, err := c.Group("foo")
+			assert.Nil(t, summary)
+			assert.ErrorContains(t, err, "could not process group response")
+			assert.Nil(t, c.CurrentGroup())
+		}
+	})
+
 	t.Run("return summary", func(t *testing.T) {
 		c := Client{
 			conn: responseConn{response: &singleLineReader{line: "211 1 2 3 foo\r\n"}},
@@ -135,4 +150,167 @@ func Test_ListGroup(t *testing.T) {
 		}
 		assert.Equal(t, expected, list)
 	})
+
+	t.Run("reports malformed lines", func(t *testing.T) {
+		c := Client{
+			conn: responseConn{response: &fullResponseReader{
+				initial: "211 3 42 44 foo",
+				payload: []string{"42", "bogus", "", "-1", "44", "."},
+			}},
+		}
+
+		list, err := c.ListGroup("foo")
+		require.NotNil(t, list)
+		assert.Equal(t, []int{42, 44}, list.ArticleNumbers)
+
+		var parseErrors ParseErrors
+		require.Equal(t, true, errors.As(err, &parseErrors))
+		assert.Equal(t, true, errors.Is(err, NntpError))
+		require.Len(t, parseErrors, 3)
+		assert.Equal(t, 2, parseErrors[0].Line)
+		assert.Equal(t, "bogus", parseErrors[0].Content)
+		assert.Equal(t, `invalid article number "bogus"`, parseErrors[0].Reason)
+	})
+
+	t.Run("discards the list after a malformed summary", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "listgroup":
+				writeLines(c, "211 2 1", "1", "2", ".")
+			case "date":
+				writeLines(c, "111 20231112130000")
+			}
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		list, err := client.ListGroup("foo")
+		assert.Nil(t, list)
+		assert.ErrorContains(t, err, `could not process group response: "2 1"`)
+		assert.Nil(t, client.CurrentGroup())
+
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+}
+
+func Test_ListGroupRange(t *testing.T) {
+	t.Run("handles 411 response", func(t *testing.T) {
+		c := Client{
+			conn: responseConn{response: &singleLineReader{line: "411 no group\r\n"}},
+		}
+
+		list, err := c.ListGroupRange("foo", 1, 10)
+		assert.Nil(t, list)
+		assert.Equal(t, true, errors.Is(err, ErrNoSuchGroup))
+	})
+
+	t.Run("sends the range", func(t *testing.T) {
+		var received [][]string
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			received = append(received, params)
+			writeLines(c, "211 3 1 44 foo", "43", "44", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		list, err := client.ListGroupRange("foo", 43, -1)
+		require.Nil(t, err)
+		assert.Equal(t, []int{43, 44}, list.ArticleNumbers)
+		assert.Equal(t, 44, list.High)
+
+		_, err = client.ListGroupRange("foo", 43, 50)
+		require.Nil(t, err)
+
+		assert.Equal(t, [][]string{{"foo", "43-"}, {"foo", "43-50"}}, received)
+	})
+}
+
+func Test_GroupState(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		switch cmd {
+		case "group":
+			if params[0] == "empty" {
+				writeLines(c, "211 0 0 0 empty")
+				return
+			}
+			writeLines(c, "211 3 10 12 "+params[0])
+		case "listgroup":
+			writeLines(c, "211 2 20 21 "+params[0], "20", "21", ".")
+		case "next":
+			writeLines(c, "223 11 <eleven@example>")
+		case "stat":
+			if len(params) == 0 {
+				writeLines(c, "223 11 <eleven@example>")
+				return
+			}
+			switch params[0] {
+			case "<other@example>":
+				writeLines(c, "223 0 <other@example>")
+			case "13":
+				writeLines(c, "423 no such article")
+			default:
+				writeLines(c, "223 "+params[0]+" <"+params[0]+"@example>")
+			}
+		case "head":
+			writeLines(c, "221 12 <twelve@example>", "Subject: twelve", ".")
+		}
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	assert.Nil(t, client.CurrentGroup())
+	number, messageId := client.CurrentArticle()
+	assert.Equal(t, 0, number)
+	assert.Equal(t, "", messageId)
+
+	_, err := client.Group("foo")
+	require.Nil(t, err)
+	assert.Equal(t, &GroupSummary{Name: "foo", Number: 3, Low: 10, High: 12}, client.CurrentGroup())
+	number, messageId = client.CurrentArticle()
+	assert.Equal(t, 10, number)
+	assert.Equal(t, "", messageId)
+
+	// Modifying the result must not modify the tracked state.
+	client.CurrentGroup().Name = "bar"
+	assert.Equal(t, "foo", client.CurrentGroup().Name)
+
+	_, _, err = client.Next()
+	require.Nil(t, err)
+	number, messageId = client.CurrentArticle()
+	assert.Equal(t, 11, number)
+	assert.Equal(t, "<eleven@example>", messageId)
+
+	// Selecting by message-id does not change the current article.
+	_, _, err = client.Stat("<other@example>")
+	require.Nil(t, err)
+	number, _ = client.CurrentArticle()
+	assert.Equal(t, 11, number)
+
+	// Failures do not change the current article.
+	_, _, err = client.Stat("13")
+	require.Error(t, err)
+	number, _ = client.CurrentArticle()
+	assert.Equal(t, 11, number)
+
+	_, err = client.Head("12")
+	require.Nil(t, err)
+	number, messageId = client.CurrentArticle()
+	assert.Equal(t, 12, number)
+	assert.Equal(t, "<twelve@example>", messageId)
+
+	_, err = client.ListGroup("baz")
+	require.Nil(t, err)
+	assert.Equal(t, "baz", client.CurrentGroup().Name)
+	number, messageId = client.CurrentArticle()
+	assert.Equal(t, 20, number)
+	assert.Equal(t, "", messageId)
+
+	_, err = client.Group("empty")
+	require.Nil(t, err)
+	number, _ = client.CurrentArticle()
+	assert.Equal(t, 0, number)
 }
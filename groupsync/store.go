@@ -0,0 +1,164 @@
+package groupsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// GroupState is the synchronization state of a single group as of the last
+// [Syncer.Commit] or [Syncer.Advance].
+type GroupState struct {
+	Name string `json:"name"`
+	// Low is the low water mark reported by the server.
+	Low int `json:"low"`
+	// High is the number of the last article that has been synchronized.
+	High    int       `json:"high"`
+	Updated time.Time `json:"updated"`
+}
+
+// StateStore persists the [GroupState] of groups. Implementations must be safe
+// for concurrent use.
+type StateStore interface {
+	// Load returns the state of the group, or nil if the group has not been
+	// synchronized yet.
+	Load(group string) (*GroupState, error)
+	// Save stores the state of the group, replacing any previous state.
+	Save(state *GroupState) error
+}
+
+// MemoryStore is a [StateStore] that keeps the states in memory, e.g. for
+// tests or short-lived processes.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]GroupState
+}
+
+// NewMemoryStore returns an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]GroupState)}
+}
+
+// Load implements [StateStore].
+func (s *MemoryStore) Load(group string) (*GroupState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, found := s.states[group]
+	if !found {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Save implements [StateStore].
+func (s *MemoryStore) Save(state *GroupState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[state.Name] = *state
+	return nil
+}
+
+// FileStore is a [StateStore] that keeps the states of all groups in a single
+// JSON file. The file is read once, on first use, and replaced atomically on
+// every save, so that it is not corrupted if writing fails. A FileStore must
+// not share its file with other stores.
+type FileStore struct {
+	path string
+
+	mu     sync.Mutex
+	states map[string]GroupState
+}
+
+// NewFileStore returns a [FileStore] backed by the file at path. The file is
+// created on the first save if it does not exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements [StateStore].
+func (s *FileStore) Load(group string) (*GroupState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return nil, err
+	}
+	state, found := s.states[group]
+	if !found {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Save implements [StateStore].
+func (s *FileStore) Save(state *GroupState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return err
+	}
+	previous, existed := s.states[state.Name]
+	s.states[state.Name] = *state
+	if err := s.write(); err != nil {
+		// Keep the cache consistent with the file.
+		if existed {
+			s.states[state.Name] = previous
+		} else {
+			delete(s.states, state.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// read loads the file into the cache, unless it has been loaded already.
+func (s *FileStore) read() error {
+	if s.states != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.states = make(map[string]GroupState)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]GroupState)
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("groupsync: %s: %w", s.path, err)
+	}
+	s.states = states
+	return nil
+}
+
+func (s *FileStore) write() error {
+	data, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
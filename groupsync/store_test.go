@@ -0,0 +1,81 @@
+package groupsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	state, err := store.Load("foo")
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	saved := &GroupState{Name: "foo", Low: 1, High: 10}
+	require.Nil(t, store.Save(saved))
+	// Later changes do not affect the stored state.
+	saved.High = 20
+
+	state, err = store.Load("foo")
+	assert.Nil(t, err)
+	assert.Equal(t, &GroupState{Name: "foo", Low: 1, High: 10}, state)
+}
+
+func Test_FileStore(t *testing.T) {
+	t.Run("persists states", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		store := NewFileStore(path)
+		state, err := store.Load("foo")
+		assert.Nil(t, err)
+		assert.Nil(t, state)
+
+		require.Nil(t, store.Save(&GroupState{Name: "foo", Low: 1, High: 10, Updated: updated}))
+		require.Nil(t, store.Save(&GroupState{Name: "bar", Low: 5, High: 7, Updated: updated}))
+		require.Nil(t, store.Save(&GroupState{Name: "foo", Low: 2, High: 12, Updated: updated}))
+
+		reopened := NewFileStore(path)
+		state, err = reopened.Load("foo")
+		assert.Nil(t, err)
+		assert.Equal(t, &GroupState{Name: "foo", Low: 2, High: 12, Updated: updated}, state)
+		state, err = reopened.Load("bar")
+		assert.Nil(t, err)
+		assert.Equal(t, 7, state.High)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.Nil(t, err)
+		assert.Len(t, entries, 1, "temporary files are removed")
+	})
+
+	t.Run("reports invalid files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.Nil(t, os.WriteFile(path, []byte("not json"), 0600))
+
+		store := NewFileStore(path)
+		state, err := store.Load("foo")
+		assert.Nil(t, state)
+		assert.ErrorContains(t, err, "groupsync: "+path)
+
+		err = store.Save(&GroupState{Name: "foo"})
+		assert.ErrorContains(t, err, "groupsync: "+path)
+	})
+
+	t.Run("keeps the cache on failed saves", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "state.json")
+
+		store := NewFileStore(path)
+		err := store.Save(&GroupState{Name: "foo", High: 10})
+		assert.NotNil(t, err)
+
+		state, err := store.Load("foo")
+		assert.Nil(t, err)
+		assert.Nil(t, state)
+	})
+}
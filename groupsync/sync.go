@@ -0,0 +1,154 @@
+// Package groupsync incrementally synchronizes groups. It remembers the last
+// article that has been synchronized in each group in a [StateStore], so that
+// subsequent synchronizations only return the articles that have arrived
+// since:
+//
+//	syncer := groupsync.New(client, groupsync.NewFileStore("state.json"))
+//	result, err := syncer.Sync("comp.lang.go")
+//	// ...
+//	for _, number := range result.Articles {
+//		// ...
+//	}
+//	err = syncer.Commit(result)
+//
+// Servers occasionally renumber groups, e.g. after a rebuild of their spool.
+// Such resets are detected when the water marks move backwards, in which case
+// all articles of the group are returned again.
+package groupsync
+
+import (
+	"time"
+
+	"github.com/popnzb/nntpclient"
+)
+
+// Result is the outcome of synchronizing a group.
+type Result struct {
+	// Group is the name of the group that was synchronized.
+	Group string
+	// Summary is the summary of the group as reported by the server.
+	Summary nntpclient.GroupSummary
+	// Articles are the numbers of the articles that arrived since the previous
+	// synchronization, in the order reported by the server.
+	Articles []int
+	// Reset indicates that the group has been renumbered since the previous
+	// synchronization. Articles then holds all articles of the group.
+	Reset bool
+	// Previous is the state before the synchronization, or nil if the group
+	// has not been synchronized before.
+	Previous *GroupState
+}
+
+// Syncer synchronizes groups using a [nntpclient.Client] and persists their
+// state in a [StateStore].
+type Syncer struct {
+	client *nntpclient.Client
+	store  StateStore
+	now    func() time.Time
+}
+
+// New returns a [Syncer] that uses the given client and store.
+func New(client *nntpclient.Client, store StateStore) *Syncer {
+	return &Syncer{client: client, store: store, now: time.Now}
+}
+
+// Sync selects the group and returns the articles that have arrived since the
+// state of the group was last committed. On the first synchronization, and
+// after a reset, all articles of the group are returned. The state is not
+// updated until the result is passed to [Syncer.Commit], so that articles
+// are returned again if processing them fails. If the server reports
+// malformed article numbers, the [nntpclient.ParseErrors] is returned instead
+// of a result, so that the state never skips articles that were not listed.
+func (s *Syncer) Sync(group string) (*Result, error) {
+	previous, err := s.store.Load(group)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.client.Group(group)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Group: group, Summary: *summary, Previous: previous}
+	if summary.Number == 0 {
+		// The water marks of empty groups are unreliable, see RFC 3977
+		// §6.1.1.2, so they are not used to detect resets.
+		return result, nil
+	}
+
+	var list *nntpclient.GroupList
+	switch {
+	case previous == nil:
+		list, err = s.client.ListGroup(group)
+	case isReset(previous, summary):
+		result.Reset = true
+		list, err = s.client.ListGroup(group)
+	case summary.High <= previous.High:
+		return result, nil
+	default:
+		list, err = s.client.ListGroupRange(group, previous.High+1, -1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Summary = list.GroupSummary
+	if previous == nil || result.Reset {
+		result.Articles = list.ArticleNumbers
+		return result, nil
+	}
+	// Servers may return articles outside of the requested range.
+	for _, number := range list.ArticleNumbers {
+		if number > previous.High {
+			result.Articles = append(result.Articles, number)
+		}
+	}
+	return result, nil
+}
+
+// isReset indicates if the water marks of the group moved backwards, which
+// servers do not do unless the group has been renumbered.
+func isReset(previous *GroupState, summary *nntpclient.GroupSummary) bool {
+	return summary.High < previous.High || summary.Low < previous.Low
+}
+
+// Commit records that all articles of the result have been synchronized.
+func (s *Syncer) Commit(result *Result) error {
+	high := 0
+	if result.Summary.Number > 0 {
+		high = result.Summary.High
+	}
+	if len(result.Articles) > 0 {
+		high = max(high, result.Articles[len(result.Articles)-1])
+	}
+	if result.Previous != nil && !result.Reset {
+		high = max(high, result.Previous.High)
+	}
+	return s.save(result, high)
+}
+
+// Advance records that the articles of the result up to and including number
+// have been synchronized, e.g. to checkpoint the progress through a large
+// result. A subsequent [Syncer.Sync] returns the articles after number.
+func (s *Syncer) Advance(result *Result, number int) error {
+	return s.save(result, number)
+}
+
+func (s *Syncer) save(result *Result, high int) error {
+	low := result.Summary.Low
+	if result.Summary.Number == 0 {
+		// Keep the previous low water mark, as that of an empty group is
+		// unreliable.
+		low = 0
+		if result.Previous != nil {
+			low = result.Previous.Low
+		}
+	}
+	return s.store.Save(&GroupState{
+		Name:    result.Group,
+		Low:     low,
+		High:    high,
+		Updated: s.now(),
+	})
+}
@@ -0,0 +1,282 @@
+package groupsync
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/popnzb/nntpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer serves a single group, `foo`, with the articles in numbers.
+type fakeServer struct {
+	mu       sync.Mutex
+	numbers  []int
+	garbage  []string
+	commands []string
+}
+
+func (s *fakeServer) setArticles(numbers ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.numbers = numbers
+}
+
+// setGarbage sets lines that are appended to the article numbers of the
+// `LISTGROUP` responses.
+func (s *fakeServer) setGarbage(lines ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.garbage = lines
+}
+
+// takeCommands returns the commands received since the last call.
+func (s *fakeServer) takeCommands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	commands := s.commands
+	s.commands = nil
+	return commands
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+	io.WriteString(c, "200 welcome\r\n")
+
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		s.mu.Lock()
+		s.commands = append(s.commands, scanner.Text())
+		s.respond(c, strings.Fields(scanner.Text()))
+		s.mu.Unlock()
+	}
+}
+
+func (s *fakeServer) respond(c net.Conn, fields []string) {
+	command := strings.ToUpper(fields[0])
+	if (command != "GROUP" && command != "LISTGROUP") || len(fields) < 2 {
+		io.WriteString(c, "500 unknown command\r\n")
+		return
+	}
+	if fields[1] != "foo" {
+		io.WriteString(c, "411 no such group\r\n")
+		return
+	}
+
+	low, high := 0, 0
+	if len(s.numbers) > 0 {
+		low, high = s.numbers[0], s.numbers[len(s.numbers)-1]
+	}
+	fmt.Fprintf(c, "211 %d %d %d foo\r\n", len(s.numbers), low, high)
+	if command == "GROUP" {
+		return
+	}
+
+	from, to := 0, int(^uint(0)>>1)
+	if len(fields) > 2 {
+		lowText, highText, _ := strings.Cut(fields[2], "-")
+		from, _ = strconv.Atoi(lowText)
+		if highText != "" {
+			to, _ = strconv.Atoi(highText)
+		}
+	}
+	for _, number := range s.numbers {
+		if number >= from && number <= to {
+			fmt.Fprintf(c, "%d\r\n", number)
+		}
+	}
+	for _, line := range s.garbage {
+		io.WriteString(c, line+"\r\n")
+	}
+	io.WriteString(c, ".\r\n")
+}
+
+func newSyncer(t *testing.T, store StateStore) (*Syncer, *fakeServer) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			require.Nil(t, err)
+			go server.serve(conn)
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	client, err := nntpclient.NewWithPort("127.0.0.1", port)
+	require.Nil(t, err)
+	require.Nil(t, client.Connect())
+	t.Cleanup(func() { client.Close() })
+
+	syncer := New(client, store)
+	syncer.now = func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return syncer, server
+}
+
+func Test_Sync(t *testing.T) {
+	t.Run("returns new articles", func(t *testing.T) {
+		store := NewMemoryStore()
+		syncer, server := newSyncer(t, store)
+		server.setArticles(1, 2, 4)
+
+		result, err := syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 4}, result.Articles)
+		assert.Equal(t, false, result.Reset)
+		assert.Nil(t, result.Previous)
+		assert.Equal(t, []string{"GROUP foo", "LISTGROUP foo"}, server.takeCommands())
+		require.Nil(t, syncer.Commit(result))
+
+		state, err := store.Load("foo")
+		require.Nil(t, err)
+		expected := &GroupState{
+			Name:    "foo",
+			Low:     1,
+			High:    4,
+			Updated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		assert.Equal(t, expected, state)
+
+		// Nothing new has arrived.
+		result, err = syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Nil(t, result.Articles)
+		assert.Equal(t, expected, result.Previous)
+		assert.Equal(t, []string{"GROUP foo"}, server.takeCommands())
+		require.Nil(t, syncer.Commit(result))
+
+		server.setArticles(2, 4, 5, 7)
+		result, err = syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Equal(t, []int{5, 7}, result.Articles)
+		assert.Equal(t, false, result.Reset)
+		assert.Equal(t, []string{"GROUP foo", "LISTGROUP foo 5-"}, server.takeCommands())
+		require.Nil(t, syncer.Commit(result))
+
+		state, err = store.Load("foo")
+		require.Nil(t, err)
+		assert.Equal(t, 2, state.Low)
+		assert.Equal(t, 7, state.High)
+	})
+
+	t.Run("fails on malformed article numbers", func(t *testing.T) {
+		store := NewMemoryStore()
+		syncer, server := newSyncer(t, store)
+		server.setArticles(1, 2)
+		server.setGarbage("bogus")
+
+		result, err := syncer.Sync("foo")
+		assert.Nil(t, result)
+		var parseErrors nntpclient.ParseErrors
+		assert.Equal(t, true, errors.As(err, &parseErrors))
+
+		state, err := store.Load("foo")
+		require.Nil(t, err)
+		assert.Nil(t, state)
+	})
+
+	t.Run("does not update the state until committed", func(t *testing.T) {
+		syncer, server := newSyncer(t, NewMemoryStore())
+		server.setArticles(1, 2)
+
+		result, err := syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Equal(t, []int{1, 2}, result.Articles)
+
+		result, err = syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Equal(t, []int{1, 2}, result.Articles)
+	})
+
+	t.Run("advances partially", func(t *testing.T) {
+		syncer, server := newSyncer(t, NewMemoryStore())
+		server.setArticles(1, 2, 3, 4)
+
+		result, err := syncer.Sync("foo")
+		require.Nil(t, err)
+		require.Nil(t, syncer.Advance(result, 2))
+
+		result, err = syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Equal(t, []int{3, 4}, result.Articles)
+		assert.Equal(t, false, result.Reset)
+	})
+
+	t.Run("detects resets", func(t *testing.T) {
+		store := NewMemoryStore()
+		syncer, server := newSyncer(t, store)
+
+		tests := []struct {
+			name     string
+			previous GroupState
+		}{
+			{name: "high water mark decreased", previous: GroupState{Low: 1, High: 100}},
+			{name: "low water mark decreased", previous: GroupState{Low: 3, High: 4}},
+		}
+		server.setArticles(1, 2, 5)
+
+		for _, test := range tests {
+			test.previous.Name = "foo"
+			require.Nil(t, store.Save(&test.previous))
+
+			result, err := syncer.Sync("foo")
+			require.Nil(t, err, test.name)
+			assert.Equal(t, true, result.Reset, test.name)
+			assert.Equal(t, []int{1, 2, 5}, result.Articles, test.name)
+			assert.Equal(t, []string{"GROUP foo", "LISTGROUP foo"}, server.takeCommands(), test.name)
+
+			require.Nil(t, syncer.Commit(result))
+			state, err := store.Load("foo")
+			require.Nil(t, err)
+			assert.Equal(t, 5, state.High, test.name)
+		}
+	})
+
+	t.Run("handles empty groups", func(t *testing.T) {
+		store := NewMemoryStore()
+		syncer, server := newSyncer(t, store)
+		require.Nil(t, store.Save(&GroupState{Name: "foo", Low: 3, High: 10}))
+
+		result, err := syncer.Sync("foo")
+		require.Nil(t, err)
+		assert.Nil(t, result.Articles)
+		assert.Equal(t, false, result.Reset)
+		assert.Equal(t, []string{"GROUP foo"}, server.takeCommands())
+
+		require.Nil(t, syncer.Commit(result))
+		state, err := store.Load("foo")
+		require.Nil(t, err)
+		assert.Equal(t, 3, state.Low)
+		assert.Equal(t, 10, state.High)
+	})
+
+	t.Run("returns errors", func(t *testing.T) {
+		syncer, _ := newSyncer(t, NewMemoryStore())
+
+		result, err := syncer.Sync("bar")
+		assert.Nil(t, result)
+		assert.Equal(t, true, errors.Is(err, nntpclient.ErrNoSuchGroup))
+
+		syncer.store = NewFileStore(t.TempDir())
+		result, err = syncer.Sync("foo")
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+	})
+}
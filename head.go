@@ -8,6 +8,31 @@ import (
 // Head retrieves only the headers for an article. The id parameter is
 // handled in the same way as it is in [Article].
 func (c *Client) Head(id string) (textproto.MIMEHeader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	headers, err := c.head(id)
+	if err != nil {
+		return nil, err
+	}
+	return headers.MIMEHeader(), nil
+}
+
+// HeadBlock retrieves only the headers for an article, retaining their
+// original order and case. The id parameter is handled in the same way as it
+// is in [Article].
+func (c *Client) HeadBlock(id string) (HeaderBlock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.head(id)
+}
+
+func (c *Client) head(id string) (HeaderBlock, error) {
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
+	}
+
 	var cmd string
 	if id == "" {
 		cmd = "HEAD"
@@ -34,6 +59,7 @@ func (c *Client) Head(id string) (textproto.MIMEHeader, error) {
 	if code != 221 {
 		return nil, UnexpectedError(code, message)
 	}
+	c.trackArticle(id, message)
 
 	return c.readHeaders()
 }
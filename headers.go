@@ -0,0 +1,171 @@
+package nntpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/textproto"
+	"strings"
+)
+
+// HeaderField is a single header as it was received from the server. The name
+// retains its original case, and folded values have been unfolded.
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// HeaderBlock is a list of headers in the order they were received from the
+// server. Unlike a [textproto.MIMEHeader], it retains the original case of
+// the header names and the relative order of different headers.
+type HeaderBlock []HeaderField
+
+// Get returns the value of the first header with the given name. Names are
+// compared case-insensitively. If there is no such header, an empty string is
+// returned.
+func (h HeaderBlock) Get(name string) string {
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Values returns the values of all headers with the given name, in the order
+// they were received. Names are compared case-insensitively.
+func (h HeaderBlock) Values(name string) []string {
+	var values []string
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			values = append(values, field.Value)
+		}
+	}
+	return values
+}
+
+// MIMEHeader converts the list into a map keyed by the canonical header names.
+func (h HeaderBlock) MIMEHeader() textproto.MIMEHeader {
+	result := make(textproto.MIMEHeader, len(h))
+	for _, field := range h {
+		result.Add(field.Name, field.Value)
+	}
+	return result
+}
+
+// Decode returns a copy of the list in which the RFC 2047 encoded words of
+// every value are decoded, e.g. `=?UTF-8?Q?caf=C3=A9?=` becomes `café`. The
+// charsets UTF-8, ISO-8859-1, and US-ASCII are supported. Values that cannot
+// be decoded are copied unchanged.
+func (h HeaderBlock) Decode() HeaderBlock {
+	result := make(HeaderBlock, len(h))
+	for i, field := range h {
+		result[i] = HeaderField{Name: field.Name, Value: decodeHeader(field.Value, nil)}
+	}
+	return result
+}
+
+// HeaderParseError is returned when a header block contains a line that
+// cannot be parsed as a header. It can be checked with [errors.As], and
+// [errors.Is] with [NntpError].
+type HeaderParseError struct {
+	// Line is the number of the offending line within the header block,
+	// starting at 1.
+	Line int
+	// Content is the offending line without its line terminator.
+	Content string
+	// Reason describes what is wrong with the line.
+	Reason string
+}
+
+func (e *HeaderParseError) Error() string {
+	return fmt.Sprintf("malformed headers, found %s on line %d: %q", e.Reason, e.Line, e.Content)
+}
+
+func (e *HeaderParseError) Unwrap() error {
+	return NntpError
+}
+
+// ReadHeaderBlock parses a header block in the same manner as [ReadHeaders],
+// but returns the headers as an ordered list.
+//
+// If a malformed line is encountered, reading continues until the end of the
+// header block, so that the remainder of the response can still be read, and
+// a [HeaderParseError] describing the first malformed line is returned.
+// Lines are accepted whether or not the colon is followed by whitespace.
+func ReadHeaderBlock(reader MultibyteReader) (HeaderBlock, int, error) {
+	offset := 0
+	lineNumber := 0
+	result := make(HeaderBlock, 0)
+	var parseErr *HeaderParseError
+
+	for {
+		readBytes, err := reader.ReadBytes(lineTerminatorByte)
+		offset += len(readBytes)
+		lineNumber++
+
+		if err != nil {
+			if err == io.EOF {
+				return nil, offset, ErrUnexpectedEOF
+			}
+			return nil, offset, err
+		}
+
+		line := bytes.TrimSuffix(bytes.TrimSuffix(readBytes, []byte("\n")), []byte("\r"))
+		// An empty line separates a header block from a body block, and a single
+		// dot follows an informational command like "HEAD".
+		if len(line) == 0 || bytes.Equal(line, []byte(".")) {
+			break
+		}
+		if parseErr != nil {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			// Line starts with a space character or a tab character.
+			// Therefore, it must be a folded value.
+			if len(result) == 0 {
+				parseErr = &HeaderParseError{lineNumber, string(line), "folded value without name"}
+				continue
+			}
+			result[len(result)-1].Value += string(line)
+			continue
+		}
+
+		name, value, found := bytes.Cut(line, []byte(":"))
+		// Obsolete syntax permits whitespace between the name and the colon.
+		name = bytes.TrimRight(name, " \t")
+		switch {
+		case !found:
+			parseErr = &HeaderParseError{lineNumber, string(line), "line without colon"}
+		case len(name) == 0:
+			parseErr = &HeaderParseError{lineNumber, string(line), "empty header name"}
+		case bytes.ContainsAny(name, " \t"):
+			parseErr = &HeaderParseError{lineNumber, string(line), "whitespace in header name"}
+		default:
+			result = append(result, HeaderField{
+				Name:  string(name),
+				Value: string(bytes.TrimLeft(value, " \t")),
+			})
+		}
+	}
+
+	if parseErr != nil {
+		return nil, offset, parseErr
+	}
+	return result, offset, nil
+}
+
+// decodeHeader decodes RFC 2047 encoded words. Charsets other than UTF-8,
+// ISO-8859-1, and US-ASCII are converted by charsetReader, if it is not nil.
+// If the value cannot be decoded, e.g. because of an unsupported charset, it
+// is returned as is.
+func decodeHeader(value string, charsetReader CharsetReader) string {
+	decoder := mime.WordDecoder{CharsetReader: charsetReader}
+	decoded, err := decoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
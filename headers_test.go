@@ -0,0 +1,138 @@
+package nntpclient
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReadHeaderBlock(t *testing.T) {
+	read := func(input string) (HeaderBlock, int, error) {
+		return ReadHeaderBlock(bufio.NewReader(strings.NewReader(input)))
+	}
+
+	t.Run("retains order and case", func(t *testing.T) {
+		input := "X-Zed: one\r\nsubject: two\r\nX-ZED: three\r\n\r\nbody\r\n"
+		headers, offset, err := read(input)
+		require.Nil(t, err)
+
+		expected := HeaderBlock{
+			{Name: "X-Zed", Value: "one"},
+			{Name: "subject", Value: "two"},
+			{Name: "X-ZED", Value: "three"},
+		}
+		assert.Equal(t, expected, headers)
+		assert.Equal(t, strings.Index(input, "body"), offset)
+	})
+
+	t.Run("accepts unusual spacing", func(t *testing.T) {
+		input := "Foo:foo\r\nBar :\tbar\r\nEmpty:\r\nFolded: a\r\n\tb\r\n.\r\n"
+		headers, _, err := read(input)
+		require.Nil(t, err)
+
+		expected := HeaderBlock{
+			{Name: "Foo", Value: "foo"},
+			{Name: "Bar", Value: "bar"},
+			{Name: "Empty", Value: ""},
+			{Name: "Folded", Value: "a\tb"},
+		}
+		assert.Equal(t, expected, headers)
+	})
+
+	t.Run("accepts bare line feeds", func(t *testing.T) {
+		headers, _, err := read("Foo: foo\nBar: bar\n\n")
+		require.Nil(t, err)
+		assert.Equal(t, HeaderBlock{{"Foo", "foo"}, {"Bar", "bar"}}, headers)
+	})
+
+	t.Run("returns parse errors", func(t *testing.T) {
+		tests := []struct {
+			input  string
+			line   int
+			reason string
+		}{
+			{"\tfoo: foo\r\n\r\n", 1, "folded value without name"},
+			{"Foo: foo\r\nno colon here\r\n\r\n", 2, "line without colon"},
+			{": foo\r\n\r\n", 1, "empty header name"},
+			{"Foo Bar: foo\r\n\r\n", 1, "whitespace in header name"},
+		}
+
+		for _, test := range tests {
+			t.Run(test.reason, func(t *testing.T) {
+				headers, offset, err := read(test.input)
+				assert.Nil(t, headers)
+				assert.Equal(t, len(test.input), offset)
+
+				var parseErr *HeaderParseError
+				require.Equal(t, true, errors.As(err, &parseErr))
+				assert.Equal(t, test.line, parseErr.Line)
+				assert.Equal(t, test.reason, parseErr.Reason)
+				assert.Equal(t, true, errors.Is(err, NntpError))
+				assert.ErrorContains(t, err, "malformed headers, found "+test.reason)
+			})
+		}
+	})
+
+	t.Run("reports the first malformed line", func(t *testing.T) {
+		_, _, err := read("bad one\r\nbad two\r\n\r\n")
+		assert.EqualError(t, err, `malformed headers, found line without colon on line 1: "bad one"`)
+	})
+
+	t.Run("returns error for EOF", func(t *testing.T) {
+		headers, _, err := read("Foo: foo\r\n")
+		assert.Nil(t, headers)
+		assert.Equal(t, true, errors.Is(err, ErrUnexpectedEOF))
+	})
+}
+
+func Test_HeaderBlock(t *testing.T) {
+	headers := HeaderBlock{
+		{Name: "Subject", Value: "=?UTF-8?Q?caf=C3=A9?= time"},
+		{Name: "x-test", Value: "one"},
+		{Name: "X-Test", Value: "two"},
+		{Name: "X-Unknown", Value: "=?x-unknown?Q?foo?="},
+	}
+
+	t.Run("gets values", func(t *testing.T) {
+		assert.Equal(t, "one", headers.Get("X-TEST"))
+		assert.Equal(t, "", headers.Get("missing"))
+		assert.Equal(t, []string{"one", "two"}, headers.Values("x-Test"))
+		assert.Nil(t, headers.Values("missing"))
+	})
+
+	t.Run("converts to a map", func(t *testing.T) {
+		expected := textproto.MIMEHeader{
+			"Subject":   {"=?UTF-8?Q?caf=C3=A9?= time"},
+			"X-Test":    {"one", "two"},
+			"X-Unknown": {"=?x-unknown?Q?foo?="},
+		}
+		assert.Equal(t, expected, headers.MIMEHeader())
+	})
+
+	t.Run("decodes encoded words", func(t *testing.T) {
+		decoded := headers.Decode()
+		assert.Equal(t, "café time", decoded.Get("Subject"))
+		assert.Equal(t, "=?x-unknown?Q?foo?=", decoded.Get("X-Unknown"))
+		// The original is unchanged.
+		assert.Equal(t, "=?UTF-8?Q?caf=C3=A9?= time", headers.Get("Subject"))
+	})
+}
+
+func Test_HeadBlock(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		writeLines(c, "221 1 <a@example>", "subject: one", "X-Extra: two", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	headers, err := client.HeadBlock("1")
+	require.Nil(t, err)
+	assert.Equal(t, HeaderBlock{{"subject", "one"}, {"X-Extra", "two"}}, headers)
+}
@@ -6,6 +6,9 @@ import (
 
 // Help retrieves the server help page for the support capabilities.
 func (c *Client) Help() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	code, message, err := c.sendCommand("HELP")
 	if err != nil {
 		return "", err
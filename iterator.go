@@ -0,0 +1,190 @@
+package nntpclient
+
+import (
+	"bytes"
+	"errors"
+	"net/textproto"
+	"strconv"
+)
+
+// IteratedArticle is an article yielded by an [ArticleIterator]. Headers and
+// Body are only populated when requested with [IterateHeaders] and
+// [IterateBodies] respectively.
+type IteratedArticle struct {
+	Number    int
+	MessageId string
+	Headers   textproto.MIMEHeader
+	Body      []byte
+}
+
+// IteratorOption configures an [ArticleIterator].
+type IteratorOption func(it *ArticleIterator)
+
+// IterateRange limits the iteration to the articles numbered from low to high,
+// inclusive. The range is further limited to the low and high water marks
+// reported by the server when the group is selected.
+func IterateRange(low int, high int) IteratorOption {
+	return func(it *ArticleIterator) {
+		it.low = low
+		it.high = high
+	}
+}
+
+// IterateHeaders retrieves the headers of each article during the iteration.
+func IterateHeaders() IteratorOption {
+	return func(it *ArticleIterator) {
+		it.withHeaders = true
+	}
+}
+
+// IterateBodies retrieves the body of each article during the iteration.
+func IterateBodies() IteratorOption {
+	return func(it *ArticleIterator) {
+		it.withBodies = true
+	}
+}
+
+// ArticleIterator walks the articles of a group in order of their article
+// numbers. Article numbers that do not refer to an article, i.e. those for
+// which the server responds with code 423, are skipped. Instances should be
+// created with [Client.IterateGroup]:
+//
+//	it := client.IterateGroup("alt.test", IterateHeaders())
+//	for it.Next() {
+//		article := it.Article()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// The client is not locked between invocations of [ArticleIterator.Next].
+// If another goroutine selects a different group in the meantime, the
+// iterator selects its group again before retrieving the next article.
+type ArticleIterator struct {
+	client *Client
+	group  string
+
+	low         int
+	high        int
+	withHeaders bool
+	withBodies  bool
+
+	started bool
+	next    int
+	current *IteratedArticle
+	err     error
+}
+
+// IterateGroup creates an [ArticleIterator] for the named group. Without
+// options, every article from the low water mark to the high water mark of
+// the group is visited, and only the article numbers and message ids are
+// retrieved (via `STAT`).
+func (c *Client) IterateGroup(group string, opts ...IteratorOption) *ArticleIterator {
+	it := &ArticleIterator{client: c, group: group, low: 0, high: -1}
+	for _, opt := range opts {
+		opt(it)
+	}
+	return it
+}
+
+// Next advances the iterator to the next article. It returns false when the
+// iteration is complete or an error has occurred, see [ArticleIterator.Err].
+func (it *ArticleIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	c := it.client
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !it.started {
+		summary, err := c.group(it.group)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.started = true
+		it.next = max(it.low, summary.Low)
+		if it.high < 0 || it.high > summary.High {
+			it.high = summary.High
+		}
+		if summary.Number == 0 {
+			// An empty group may report a high water mark lower than its low
+			// water mark, but let's not depend on it.
+			it.high = it.next - 1
+		}
+	}
+
+	for ; it.next <= it.high; it.next++ {
+		if c.currentGroup == nil || c.currentGroup.Name != it.group {
+			if _, err := c.group(it.group); err != nil {
+				it.err = err
+				return false
+			}
+		}
+
+		article, err := it.fetch(it.next)
+		if errors.Is(err, ErrNoArticleWithNum) {
+			continue
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.current = article
+		it.next++
+		return true
+	}
+
+	it.current = nil
+	return false
+}
+
+// fetch retrieves the article with the given number using the least
+// expensive command that provides the requested parts of the article.
+func (it *ArticleIterator) fetch(number int) (*IteratedArticle, error) {
+	c := it.client
+	id := strconv.Itoa(number)
+	article := &IteratedArticle{Number: number}
+
+	var body bytes.Buffer
+	var err error
+	var headers HeaderBlock
+	switch {
+	case it.withHeaders && it.withBodies:
+		headers, err = c.article(id, &body)
+		article.Body = body.Bytes()
+	case it.withHeaders:
+		headers, err = c.head(id)
+	case it.withBodies:
+		err = c.body(id, &body)
+		article.Body = body.Bytes()
+	default:
+		_, article.MessageId, err = c.stat(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if headers != nil {
+		article.Headers = headers.MIMEHeader()
+	}
+
+	if article.MessageId == "" && c.currentArticleNumber == number {
+		article.MessageId = c.currentArticleId
+	}
+	return article, nil
+}
+
+// Article returns the article the iterator is positioned at by the most recent
+// invocation of [ArticleIterator.Next].
+func (it *ArticleIterator) Article() *IteratedArticle {
+	return it.current
+}
+
+// Err returns the error, if any, that ended the iteration.
+func (it *ArticleIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package nntpclient
+
+import "iter"
+
+// All returns the iteration as an [iter.Seq2] for use with range-over-func.
+// Each article is yielded with a nil error. If the iteration ends because of
+// an error, a final nil article is yielded with that error:
+//
+//	for article, err := range client.IterateGroup("alt.test").All() {
+//		if err != nil {
+//			// ...
+//		}
+//	}
+func (it *ArticleIterator) All() iter.Seq2[*IteratedArticle, error] {
+	return func(yield func(*IteratedArticle, error) bool) {
+		for it.Next() {
+			if !yield(it.Article(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
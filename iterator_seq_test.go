@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package nntpclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ArticleIteratorAll(t *testing.T) {
+	t.Run("yields articles and the final error", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		numbers := make([]int, 0)
+		var iterErr error
+		for article, err := range client.IterateGroup("alt.test").All() {
+			if err != nil {
+				iterErr = err
+				continue
+			}
+			numbers = append(numbers, article.Number)
+		}
+		assert.Equal(t, []int{1, 2, 4}, numbers)
+		assert.ErrorContains(t, iterErr, "unexpected response code: 503")
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		numbers := make([]int, 0)
+		for article, err := range client.IterateGroup("alt.test").All() {
+			assert.Nil(t, err)
+			numbers = append(numbers, article.Number)
+			break
+		}
+		assert.Equal(t, []int{1}, numbers)
+	})
+}
@@ -0,0 +1,173 @@
+package nntpclient
+
+import (
+	"errors"
+	"net"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// iteratorHandler serves a group, "alt.test", with articles 1, 2, 4, and 6.
+// Article 5 causes a server error. Any other group is empty.
+func iteratorHandler(t *testing.T, c net.Conn, cmd string, params []string) {
+	articles := map[string]bool{"1": true, "2": true, "4": true, "6": true}
+
+	switch cmd {
+	case "group":
+		if params[0] == "alt.test" {
+			writeLines(c, "211 4 1 6 alt.test")
+			return
+		}
+		writeLines(c, "211 0 1 0 "+params[0])
+		return
+	case "quit":
+		writeLines(c, "205 bye")
+		return
+	}
+
+	number := params[0]
+	if number == "5" {
+		writeLines(c, "503 program fault")
+		return
+	}
+	if !articles[number] {
+		writeLines(c, "423 no such article")
+		return
+	}
+
+	id := "<" + number + "@example>"
+	switch cmd {
+	case "stat":
+		writeLines(c, "223 "+number+" "+id)
+	case "head":
+		writeLines(c, "221 "+number+" "+id, "Subject: article "+number, ".")
+	case "body":
+		writeLines(c, "222 "+number+" "+id, "body "+number, ".")
+	case "article":
+		writeLines(c, "220 "+number+" "+id, "Subject: article "+number, "", "body "+number, ".")
+	}
+}
+
+func collect(it *ArticleIterator) []*IteratedArticle {
+	result := make([]*IteratedArticle, 0)
+	for it.Next() {
+		result = append(result, it.Article())
+	}
+	return result
+}
+
+func Test_ArticleIterator(t *testing.T) {
+	t.Run("walks article numbers and ids", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test", IterateRange(1, 4))
+		articles := collect(it)
+		require.Nil(t, it.Err())
+
+		expected := []*IteratedArticle{
+			{Number: 1, MessageId: "<1@example>"},
+			{Number: 2, MessageId: "<2@example>"},
+			{Number: 4, MessageId: "<4@example>"},
+		}
+		assert.Equal(t, expected, articles)
+		assert.Nil(t, it.Article())
+		assert.Equal(t, false, it.Next())
+	})
+
+	t.Run("retrieves headers", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test", IterateRange(2, 3), IterateHeaders())
+		articles := collect(it)
+		require.Nil(t, it.Err())
+
+		expected := []*IteratedArticle{{
+			Number:    2,
+			MessageId: "<2@example>",
+			Headers:   textproto.MIMEHeader{"Subject": {"article 2"}},
+		}}
+		assert.Equal(t, expected, articles)
+	})
+
+	t.Run("retrieves bodies", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test", IterateRange(0, 2), IterateBodies())
+		articles := collect(it)
+		require.Nil(t, it.Err())
+		require.Len(t, articles, 2)
+		assert.Equal(t, "<1@example>", articles[0].MessageId)
+		assert.Equal(t, "body 1\r\n", string(articles[0].Body))
+		assert.Nil(t, articles[0].Headers)
+	})
+
+	t.Run("retrieves whole articles", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test", IterateRange(6, 100), IterateHeaders(), IterateBodies())
+		articles := collect(it)
+		require.Nil(t, it.Err())
+
+		expected := []*IteratedArticle{{
+			Number:    6,
+			MessageId: "<6@example>",
+			Headers:   textproto.MIMEHeader{"Subject": {"article 6"}},
+			Body:      []byte("body 6\r\n"),
+		}}
+		assert.Equal(t, expected, articles)
+	})
+
+	t.Run("stops on errors", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test")
+		articles := collect(it)
+		assert.Len(t, articles, 3)
+		assert.ErrorContains(t, it.Err(), "unexpected response code: 503")
+		assert.Equal(t, false, it.Next())
+	})
+
+	t.Run("handles empty groups", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.empty")
+		assert.Equal(t, false, it.Next())
+		assert.Nil(t, it.Err())
+	})
+
+	t.Run("handles missing groups", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "411 no such group")
+		}
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.missing")
+		assert.Equal(t, false, it.Next())
+		assert.Equal(t, true, errors.Is(it.Err(), ErrNoSuchGroup))
+	})
+
+	t.Run("reselects its group", func(t *testing.T) {
+		server, client := getServerAndClient(t, iteratorHandler)
+		defer server.Close()
+
+		it := client.IterateGroup("alt.test", IterateRange(1, 4))
+		require.Equal(t, true, it.Next())
+
+		_, err := client.Group("alt.other")
+		require.Nil(t, err)
+
+		require.Equal(t, true, it.Next())
+		assert.Equal(t, 2, it.Article().Number)
+		assert.Equal(t, "alt.test", client.CurrentGroup().Name)
+	})
+}
@@ -1,25 +1,39 @@
 package nntpclient
 
 // Last sets the selected article to the most recent article in the
-// selected group.
-func (c *Client) Last() error {
+// selected group. The article number and message id of the newly selected
+// article are returned.
+func (c *Client) Last() (int, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("READER"); err != nil {
+		return -1, "", err
+	}
+
 	code, message, err := c.sendCommand("LAST")
 	if err != nil {
-		return err
+		return -1, "", err
 	}
 
 	switch code {
 	case 412:
-		return ErrNoGroupSelected
+		return -1, "", ErrNoGroupSelected
 	case 420:
-		return ErrCurrentArticleNumInvalid
+		return -1, "", ErrCurrentArticleNumInvalid
 	case 422:
-		return ErrNoPrevArticle
+		return -1, "", ErrNoPrevArticle
 	}
 
 	if code != 223 {
-		return UnexpectedError(code, message)
+		return -1, "", UnexpectedError(code, message)
+	}
+
+	number, messageId, err := parseArticleResponse(message)
+	if err != nil {
+		return -1, "", err
 	}
+	c.trackArticle("", message)
 
-	return nil
+	return number, messageId, nil
 }
@@ -29,15 +29,19 @@ func Test_Last(t *testing.T) {
 		writeLines(c, "404 missing")
 	}
 
+	malformedHandler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		writeLines(c, "223 ok")
+	}
+
 	successHandler := func(t *testing.T, c net.Conn, cmd string, params []string) {
-		writeLines(c, "223 next")
+		writeLines(c, "223 3 <foo.bar>")
 	}
 
 	t.Run("handles bad response", func(t *testing.T) {
 		server, client := getServerAndClient(t, badResponseHandler)
 		defer server.Close()
 
-		err := client.Last()
+		_, _, err := client.Last()
 		assert.ErrorContains(t, err, "invalid syntax")
 	})
 
@@ -45,7 +49,7 @@ func Test_Last(t *testing.T) {
 		server, client := getServerAndClient(t, error412Handler)
 		defer server.Close()
 
-		err := client.Last()
+		_, _, err := client.Last()
 		assert.Equal(t, true, errors.Is(err, ErrNoGroupSelected))
 	})
 
@@ -53,7 +57,7 @@ func Test_Last(t *testing.T) {
 		server, client := getServerAndClient(t, error420Handler)
 		defer server.Close()
 
-		err := client.Last()
+		_, _, err := client.Last()
 		assert.Equal(t, true, errors.Is(err, ErrCurrentArticleNumInvalid))
 	})
 
@@ -61,7 +65,7 @@ func Test_Last(t *testing.T) {
 		server, client := getServerAndClient(t, error422Handler)
 		defer server.Close()
 
-		err := client.Last()
+		_, _, err := client.Last()
 		assert.Equal(t, true, errors.Is(err, ErrNoPrevArticle))
 	})
 
@@ -69,7 +73,7 @@ func Test_Last(t *testing.T) {
 		server, client := getServerAndClient(t, unexpectedErrorHandler)
 		defer server.Close()
 
-		err := client.Last()
+		_, _, err := client.Last()
 		assert.Equal(t, true, errors.Is(err, NntpError))
 	})
 
@@ -77,7 +81,23 @@ func Test_Last(t *testing.T) {
 		server, client := getServerAndClient(t, successHandler)
 		defer server.Close()
 
-		err := client.Last()
+		number, messageId, err := client.Last()
 		assert.Nil(t, err)
+		assert.Equal(t, 3, number)
+		assert.Equal(t, "<foo.bar>", messageId)
+
+		number, messageId = client.CurrentArticle()
+		assert.Equal(t, 3, number)
+		assert.Equal(t, "<foo.bar>", messageId)
+	})
+
+	t.Run("handles malformed success response", func(t *testing.T) {
+		server, client := getServerAndClient(t, malformedHandler)
+		defer server.Close()
+
+		number, messageId, err := client.Last()
+		assert.Equal(t, -1, number)
+		assert.Equal(t, "", messageId)
+		assert.ErrorContains(t, err, "could not process article response")
 	})
 }
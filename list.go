@@ -1,13 +1,11 @@
 package nntpclient
 
 import (
-	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
-
-	"github.com/spf13/cast"
 )
 
 // ListGroup represents a group information line from a list directive.
@@ -16,7 +14,7 @@ type ListGroup struct {
 	Name   string
 	Low    int
 	High   int
-	Status string
+	Status GroupStatus
 }
 
 // ListGroupTimes represents a group information line from a `active.times`
@@ -42,136 +40,587 @@ type ListNewsgroup struct {
 	Description string
 }
 
-func (c *Client) listCmd(cmd string) ([]byte, error) {
+// ListGroupCount represents a group information line from a `list counts`
+// directive, which is like a `list active` line with the estimated number
+// of articles in the group. See RFC 6048 §2.2.
+type ListGroupCount struct {
+	Name   string
+	Low    int
+	High   int
+	Count  int
+	Status GroupStatus
+}
+
+// ListDistribution represents a distribution from a `list distributions`
+// directive. See RFC 6048 §2.4.
+type ListDistribution struct {
+	Name        string
+	Description string
+}
+
+// ListModerator represents a line from a `list moderators` directive. The
+// address of the moderator of a group is found by replacing `%s` in the
+// Address of the first entry whose Pattern matches the group, with the group
+// name, where periods have been replaced by dashes. See RFC 6048 §2.3.
+type ListModerator struct {
+	Pattern string
+	Address string
+}
+
+// ListParseError describes a line of a list that could not be parsed. Such
+// lines are skipped, see [ParseErrors].
+type ListParseError struct {
+	// Line is the number of the line within the list, starting at 1.
+	Line    int
+	Content string
+	Reason  string
+}
+
+func (e *ListParseError) Error() string {
+	return fmt.Sprintf("malformed list line %d, %s: %q", e.Line, e.Reason, e.Content)
+}
+
+func (e *ListParseError) Unwrap() error {
+	return NntpError
+}
+
+// ParseErrors is returned by the list methods when some lines of a list
+// could not be parsed. Unlike other errors, it is returned alongside the
+// entries of the well-formed lines, so that a single malformed line does not
+// make the whole list unusable:
+//
+//	groups, err := client.ListActive("")
+//	var parseErrors nntpclient.ParseErrors
+//	if errors.As(err, &parseErrors) {
+//		// groups holds the well-formed lines.
+//	} else if err != nil {
+//		// ...
+//	}
+type ParseErrors []*ListParseError
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d malformed list lines, first: %v", len(e), e[0])
+}
+
+func (e ParseErrors) Unwrap() []error {
+	result := make([]error, len(e))
+	for i, err := range e {
+		result[i] = err
+	}
+	return result
+}
+
+// isParseErrors indicates if err only reports malformed lines, in which case
+// the entries of the well-formed lines are returned along with it.
+func isParseErrors(err error) bool {
+	var parseErrors ParseErrors
+	return errors.As(err, &parseErrors)
+}
+
+// lineWriter passes each line written by [ReadBody] to fn, without the line
+// terminator and with dot-stuffing removed. Once fn returns an error, the
+// remaining lines are discarded, so that the response is still read in full
+// and the connection remains usable.
+type lineWriter struct {
+	fn  func(line string) error
+	err error
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if w.err == nil {
+		line := strings.TrimRight(string(p), "\r\n")
+		if strings.HasPrefix(line, "..") {
+			line = line[1:]
+		}
+		w.err = w.fn(line)
+	}
+	return len(p), nil
+}
+
+// readLines reads a multi-line response and invokes fn for every line as
+// it is read, instead of buffering the response. An error returned by fn is
+// returned once the response has been read.
+func (c *Client) readLines(fn func(line string) error) error {
+	writer := &lineWriter{fn: fn}
+	if err := c.readBody(writer); err != nil {
+		return err
+	}
+	return writer.err
+}
+
+// listLines sends a `LIST` command and invokes fn for every line of the
+// response, see [Client.readLines].
+func (c *Client) listLines(cmd string, fn func(line string) error) error {
 	code, message, err := c.sendCommand(cmd)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if code != 215 {
-		return nil, UnexpectedError(code, message)
+		return UnexpectedError(code, message)
 	}
+	return c.readLines(fn)
+}
 
-	var body bytes.Buffer
-	err = c.readBody(&body)
+// parseLines returns a function for [Client.readLines] that parses every line
+// with parse and passes the result to fn. Lines that cannot be parsed are
+// skipped and added to errs.
+func parseLines[T any](parse func(string) (T, error), fn func(T) error, errs *ParseErrors) func(string) error {
+	lineNumber := 0
+	return func(line string) error {
+		lineNumber++
+		item, err := parse(line)
+		if err != nil {
+			*errs = append(*errs, &ListParseError{Line: lineNumber, Content: line, Reason: err.Error()})
+			return nil
+		}
+		return fn(item)
+	}
+}
 
-	return body.Bytes(), err
+// readParsed reads a multi-line response and invokes fn with every line that
+// parse accepts. If any lines are malformed, a [ParseErrors] is returned once
+// the response has been read.
+func readParsed[T any](c *Client, parse func(string) (T, error), fn func(T) error) error {
+	var errs ParseErrors
+	if err := c.readLines(parseLines(parse, fn, &errs)); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
-func bodyToListGroup(body []byte) map[string]ListGroup {
-	result := make(map[string]ListGroup)
-	scanner := bufio.NewScanner(bytes.NewReader(body))
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		result[parts[0]] = ListGroup{
-			Name:   parts[0],
-			Low:    cast.ToInt(parts[2]),
-			High:   cast.ToInt(parts[1]),
-			Status: parts[3],
-		}
+// listParsed sends a `LIST` command and reads the response with
+// [readParsed].
+func listParsed[T any](c *Client, cmd string, parse func(string) (T, error), fn func(T) error) error {
+	code, message, err := c.sendCommand(cmd)
+	if err != nil {
+		return err
 	}
-	return result
+	if code != 215 {
+		return UnexpectedError(code, message)
+	}
+	return readParsed(c, parse, fn)
 }
 
-// ListActive retrieves a list of active groups. The wildmat parameter can
-// be the empty string to indicate "all groups."
-func (c *Client) ListActive(wildmat string) (map[string]ListGroup, error) {
-	var cmd string
+// collectByName returns a function for the list methods that adds every entry
+// to result, keyed by name.
+func collectByName[T any](result map[string]T, name func(T) string) func(T) error {
+	return func(item T) error {
+		result[name(item)] = item
+		return nil
+	}
+}
+
+// listCommand returns the `LIST` command for the keyword, restricted to the
+// wildmat if it is not empty.
+func listCommand(keyword string, wildmat string) string {
 	if wildmat == "" {
-		cmd = "LIST ACTIVE"
-	} else {
-		cmd = fmt.Sprintf("LIST ACTIVE %s", wildmat)
+		return "LIST " + keyword
+	}
+	return fmt.Sprintf("LIST %s %s", keyword, wildmat)
+}
+
+// splitFields splits a line into at least min whitespace separated fields.
+func splitFields(line string, min int) ([]string, error) {
+	fields := strings.Fields(line)
+	if len(fields) < min {
+		return nil, fmt.Errorf("expected %d fields, found %d", min, len(fields))
+	}
+	return fields, nil
+}
+
+// parseNumber parses a non-negative number, e.g. an article number.
+func parseNumber(value string, name string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
 	}
+	return number, nil
+}
 
-	body, err := c.listCmd(cmd)
+func parseListGroup(line string) (ListGroup, error) {
+	fields, err := splitFields(line, 4)
+	if err != nil {
+		return ListGroup{}, err
+	}
+	high, err := parseNumber(fields[1], "high water mark")
+	if err != nil {
+		return ListGroup{}, err
+	}
+	low, err := parseNumber(fields[2], "low water mark")
 	if err != nil {
+		return ListGroup{}, err
+	}
+	return ListGroup{Name: fields[0], Low: low, High: high, Status: GroupStatus(fields[3])}, nil
+}
+
+// ListActive retrieves a list of active groups. The wildmat parameter can
+// be the empty string to indicate "all groups." See [Client.ListActiveFunc]
+// for processing large lists without holding them in memory. Malformed
+// lines are reported by a [ParseErrors], which is returned along with the
+// other groups.
+func (c *Client) ListActive(wildmat string) (map[string]ListGroup, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]ListGroup)
+	err := c.listActive(wildmat, collectByName(result, func(group ListGroup) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
 		return nil, err
 	}
+	return result, err
+}
+
+// ListActiveFunc is like [Client.ListActive], but invokes fn for every group
+// as soon as it has been read, instead of collecting the groups. If fn
+// returns an error, fn is not invoked again and the error is returned once
+// the remainder of the list has been read and discarded. Malformed lines are
+// skipped and reported by a [ParseErrors] once the list has been read.
+//
+// The client is locked until the list has been read, so fn must not use
+// the client.
+func (c *Client) ListActiveFunc(wildmat string, fn func(ListGroup) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.listActive(wildmat, fn)
+}
+
+func (c *Client) listActive(wildmat string, fn func(ListGroup) error) error {
+	if err := c.requireCapability("LIST", "ACTIVE"); err != nil {
+		return err
+	}
+
+	return listParsed(c, listCommand("ACTIVE", wildmat), parseListGroup, fn)
+}
 
-	result := bodyToListGroup(body)
+// parseListGroupTimes parses an `active.times` line. The creator is
+// optional, as some servers omit it.
+func parseListGroupTimes(line string) (ListGroupTimes, error) {
+	fields, err := splitFields(line, 2)
+	if err != nil {
+		return ListGroupTimes{}, err
+	}
+	created, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return ListGroupTimes{}, fmt.Errorf("invalid creation time %q", fields[1])
+	}
 
+	result := ListGroupTimes{Name: fields[0], Created: time.Unix(created, 0).UTC()}
+	if len(fields) > 2 {
+		result.Creator = fields[2]
+	}
 	return result, nil
 }
 
 // ListActiveTimes retrieves a list of groups, when they were created, and by
 // whom. The wildmat parameter can be the empty string to indicate "all groups."
+// Malformed lines are reported as described for [Client.ListActive].
 func (c *Client) ListActiveTimes(wildmat string) (map[string]ListGroupTimes, error) {
-	var cmd string
-	if wildmat == "" {
-		cmd = "LIST ACTIVE.TIMES"
-	} else {
-		cmd = fmt.Sprintf("LIST ACTIVE.TIMES %s", wildmat)
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	body, err := c.listCmd(cmd)
-	if err != nil {
+	result := make(map[string]ListGroupTimes)
+	err := c.listActiveTimes(wildmat, collectByName(result, func(group ListGroupTimes) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
 		return nil, err
 	}
+	return result, err
+}
 
-	result := make(map[string]ListGroupTimes)
-	scanner := bufio.NewScanner(bytes.NewReader(body))
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		result[parts[0]] = ListGroupTimes{
-			Name:    parts[0],
-			Created: time.Unix(cast.ToInt64(parts[1]), 0).UTC(),
-			Creator: parts[2],
-		}
+// ListActiveTimesFunc is like [Client.ListActiveTimes], but invokes fn for
+// every group as soon as it has been read. See [Client.ListActiveFunc].
+func (c *Client) ListActiveTimesFunc(wildmat string, fn func(ListGroupTimes) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.listActiveTimes(wildmat, fn)
+}
+
+func (c *Client) listActiveTimes(wildmat string, fn func(ListGroupTimes) error) error {
+	if err := c.requireCapability("LIST", "ACTIVE.TIMES"); err != nil {
+		return err
 	}
 
-	return result, nil
+	return listParsed(c, listCommand("ACTIVE.TIMES", wildmat), parseListGroupTimes, fn)
+}
+
+func parseListDistribPattern(line string) (ListDistribPattern, error) {
+	weight, rest, found := strings.Cut(line, ":")
+	wildmat, value, foundValue := strings.Cut(rest, ":")
+	if !found || !foundValue {
+		return ListDistribPattern{}, errors.New("expected 3 colon separated fields")
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(weight))
+	if err != nil {
+		return ListDistribPattern{}, fmt.Errorf("invalid weight %q", weight)
+	}
+	return ListDistribPattern{Weight: number, Wildmat: wildmat, Value: value}, nil
 }
 
 // ListDistribPats retrieves a list of distribution header patterns supported
-// by the server.
+// by the server. Malformed lines are reported as described for
+// [Client.ListActive].
 func (c *Client) ListDistribPats() ([]ListDistribPattern, error) {
-	body, err := c.listCmd("LIST DISTRIB.PATS")
-	if err != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "DISTRIB.PATS"); err != nil {
 		return nil, err
 	}
 
 	result := make([]ListDistribPattern, 0)
-	scanner := bufio.NewScanner(bytes.NewReader(body))
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, ":")
-		pattern := ListDistribPattern{
-			Weight:  cast.ToInt(parts[0]),
-			Wildmat: parts[1],
-			Value:   parts[2],
-		}
+	err := listParsed(c, "LIST DISTRIB.PATS", parseListDistribPattern, func(pattern ListDistribPattern) error {
 		result = append(result, pattern)
+		return nil
+	})
+	if err != nil && !isParseErrors(err) {
+		return nil, err
 	}
+	return result, err
+}
 
-	return result, nil
+// parseListNewsgroup parses a `newsgroups` line. The description is empty if
+// the line only holds the name of the group.
+func parseListNewsgroup(line string) (ListNewsgroup, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ListNewsgroup{}, errors.New("missing group name")
+	}
+	name, description := line, ""
+	if sepIndex := strings.IndexAny(line, " \t"); sepIndex >= 0 {
+		name, description = line[:sepIndex], strings.TrimSpace(line[sepIndex:])
+	}
+	return ListNewsgroup{Name: name, Description: description}, nil
 }
 
 // ListNewsgroups retrieves a list of newsgroups known by the server. The
 // wildmat parameter can be the empty string to indicate "all groups". The
 // result is a map of group names to group names and group descriptions.
+// Malformed lines are reported as described for [Client.ListActive]. If the
+// server does not support `LIST NEWSGROUPS`, [Client.XGTitle] is used
+// instead.
 func (c *Client) ListNewsgroups(wildmat string) (map[string]ListNewsgroup, error) {
-	var cmd string
-	if wildmat == "" {
-		cmd = "LIST NEWSGROUPS"
-	} else {
-		cmd = fmt.Sprintf("LIST NEWSGROUPS %s", wildmat)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]ListNewsgroup)
+	err := c.listNewsgroups(wildmat, collectByName(result, func(group ListNewsgroup) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+// ListNewsgroupsFunc is like [Client.ListNewsgroups], but invokes fn for
+// every group as soon as it has been read. See [Client.ListActiveFunc].
+func (c *Client) ListNewsgroupsFunc(wildmat string, fn func(ListNewsgroup) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.listNewsgroups(wildmat, fn)
+}
+
+// listNewsgroups falls back to `XGTITLE` if the server does not support
+// `LIST NEWSGROUPS`.
+func (c *Client) listNewsgroups(wildmat string, fn func(ListNewsgroup) error) error {
+	err := c.requireCapability("LIST", "NEWSGROUPS")
+	if errors.Is(err, ErrNotSupported) {
+		return c.xgtitle(wildmat, fn)
+	}
+	if err != nil {
+		return err
+	}
+
+	code, message, err := c.sendCommand(listCommand("NEWSGROUPS", wildmat))
+	if err != nil {
+		return err
 	}
+	if code == 500 || code == 501 {
+		return c.xgtitle(wildmat, fn)
+	}
+	if code != 215 {
+		return UnexpectedError(code, message)
+	}
+	return readParsed(c, parseListNewsgroup, fn)
+}
 
-	body, err := c.listCmd(cmd)
+func parseListGroupCount(line string) (ListGroupCount, error) {
+	fields, err := splitFields(line, 5)
+	if err != nil {
+		return ListGroupCount{}, err
+	}
+	high, err := parseNumber(fields[1], "high water mark")
 	if err != nil {
+		return ListGroupCount{}, err
+	}
+	low, err := parseNumber(fields[2], "low water mark")
+	if err != nil {
+		return ListGroupCount{}, err
+	}
+	count, err := parseNumber(fields[3], "article count")
+	if err != nil {
+		return ListGroupCount{}, err
+	}
+	return ListGroupCount{Name: fields[0], Low: low, High: high, Count: count, Status: GroupStatus(fields[4])}, nil
+}
+
+// ListCounts retrieves a list of groups along with the estimated number of
+// articles in each group. The wildmat parameter can be the empty string to
+// indicate "all groups." Malformed lines are reported as described for
+// [Client.ListActive].
+func (c *Client) ListCounts(wildmat string) (map[string]ListGroupCount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "COUNTS"); err != nil {
 		return nil, err
 	}
 
-	result := make(map[string]ListNewsgroup)
-	scanner := bufio.NewScanner(bytes.NewReader(body))
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		sepIndex := bytes.IndexAny(line, " \t")
-		name := string(line[0:sepIndex])
-		value := strings.TrimSpace(string(line[sepIndex:]))
-		result[name] = ListNewsgroup{Name: name, Description: value}
+	result := make(map[string]ListGroupCount)
+	err := listParsed(c, listCommand("COUNTS", wildmat), parseListGroupCount,
+		collectByName(result, func(group ListGroupCount) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+// ListMotd retrieves the message of the day of the server. Each line of the
+// message is terminated by a single `\n`.
+func (c *Client) ListMotd() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "MOTD"); err != nil {
+		return "", err
+	}
+
+	var motd strings.Builder
+	err := c.listLines("LIST MOTD", func(line string) error {
+		motd.WriteString(line + "\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return motd.String(), nil
+}
+
+// ListSubscriptions retrieves the list of groups the server recommends new
+// users to subscribe to, in the order given by the server.
+func (c *Client) ListSubscriptions() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "SUBSCRIPTIONS"); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	err := c.listLines("LIST SUBSCRIPTIONS", func(line string) error {
+		if name := strings.TrimSpace(line); name != "" {
+			result = append(result, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parseListDistribution(line string) (ListDistribution, error) {
+	newsgroup, err := parseListNewsgroup(line)
+	if err != nil {
+		return ListDistribution{}, errors.New("missing distribution name")
+	}
+	return ListDistribution{Name: newsgroup.Name, Description: newsgroup.Description}, nil
+}
+
+// ListDistributions retrieves the list of values for the `Distribution`
+// header that are understood by the server, along with their descriptions.
+// Malformed lines are reported as described for [Client.ListActive].
+func (c *Client) ListDistributions() ([]ListDistribution, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "DISTRIBUTIONS"); err != nil {
+		return nil, err
+	}
+
+	result := make([]ListDistribution, 0)
+	err := listParsed(c, "LIST DISTRIBUTIONS", parseListDistribution, func(distribution ListDistribution) error {
+		result = append(result, distribution)
+		return nil
+	})
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+func parseListModerator(line string) (ListModerator, error) {
+	pattern, address, found := strings.Cut(line, ":")
+	if !found || pattern == "" || address == "" {
+		return ListModerator{}, errors.New("expected pattern and address separated by a colon")
+	}
+	return ListModerator{Pattern: pattern, Address: address}, nil
+}
+
+// ListModerators retrieves the list of submission addresses for moderated
+// groups, in the order given by the server. Malformed lines are reported as
+// described for [Client.ListActive].
+func (c *Client) ListModerators() ([]ListModerator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("LIST", "MODERATORS"); err != nil {
+		return nil, err
 	}
 
+	result := make([]ListModerator, 0)
+	err := listParsed(c, "LIST MODERATORS", parseListModerator, func(moderator ListModerator) error {
+		result = append(result, moderator)
+		return nil
+	})
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+// ListRaw issues `LIST keyword argument` and returns the lines of the
+// response, with dot-stuffing removed. It can be used for keywords that
+// are not otherwise supported, e.g. vendor extensions. The argument may be
+// the empty string to omit it. The server must advertise the keyword as a
+// `LIST` capability argument if capability checks are enabled.
+func (c *Client) ListRaw(keyword string, argument string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cmd := "LIST"
+	if keyword != "" {
+		if err := c.requireCapability("LIST", strings.ToUpper(keyword)); err != nil {
+			return nil, err
+		}
+		cmd = listCommand(keyword, argument)
+	} else if err := c.requireCapability("LIST"); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	err := c.listLines(cmd, func(line string) error {
+		result = append(result, line)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
@@ -0,0 +1,65 @@
+//go:build go1.23
+
+package nntpclient
+
+import (
+	"errors"
+	"iter"
+)
+
+// errStopListing is returned to the list functions when the loop over an
+// [iter.Seq2] has been exited early.
+var errStopListing = errors.New("listing stopped")
+
+// listSeq adapts a callback-based list function to an [iter.Seq2]. Items are
+// yielded with a nil error. If the listing fails, a final zero item is
+// yielded with the error.
+func listSeq[T any](list func(fn func(T) error) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := list(func(item T) error {
+			if !yield(item, nil) {
+				return errStopListing
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopListing) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// ListActiveSeq returns the groups of [Client.ListActive] as an
+// [iter.Seq2] for use with range-over-func. Each group is yielded as soon
+// as it has been read:
+//
+//	for group, err := range client.ListActiveSeq("") {
+//		if err != nil {
+//			// ...
+//		}
+//	}
+//
+// The client is locked while looping, so the loop body must not use the
+// client. If the loop is exited early, the remainder of the list is read
+// and discarded.
+func (c *Client) ListActiveSeq(wildmat string) iter.Seq2[ListGroup, error] {
+	return listSeq(func(fn func(ListGroup) error) error {
+		return c.ListActiveFunc(wildmat, fn)
+	})
+}
+
+// ListActiveTimesSeq returns the groups of [Client.ListActiveTimes] as an
+// [iter.Seq2]. See [Client.ListActiveSeq].
+func (c *Client) ListActiveTimesSeq(wildmat string) iter.Seq2[ListGroupTimes, error] {
+	return listSeq(func(fn func(ListGroupTimes) error) error {
+		return c.ListActiveTimesFunc(wildmat, fn)
+	})
+}
+
+// ListNewsgroupsSeq returns the groups of [Client.ListNewsgroups] as an
+// [iter.Seq2]. See [Client.ListActiveSeq].
+func (c *Client) ListNewsgroupsSeq(wildmat string) iter.Seq2[ListNewsgroup, error] {
+	return listSeq(func(fn func(ListNewsgroup) error) error {
+		return c.ListNewsgroupsFunc(wildmat, fn)
+	})
+}
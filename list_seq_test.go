@@ -0,0 +1,83 @@
+//go:build go1.23
+
+package nntpclient
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListActiveSeq(t *testing.T) {
+	t.Run("yields groups", func(t *testing.T) {
+		server, client := getServerAndClient(t, listHandler)
+		defer server.Close()
+
+		names := make([]string, 0)
+		for group, err := range client.ListActiveSeq("") {
+			require.Nil(t, err)
+			names = append(names, group.Name)
+		}
+		assert.Equal(t, []string{"a.group", "b.group"}, names)
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		server, client := getServerAndClient(t, listHandler)
+		defer server.Close()
+
+		names := make([]string, 0)
+		for group, err := range client.ListActiveSeq("") {
+			require.Nil(t, err)
+			names = append(names, group.Name)
+			break
+		}
+		assert.Equal(t, []string{"a.group"}, names)
+
+		// The remaining list has been read.
+		_, err := client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("yields the final error", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "503 no active file")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		count := 0
+		for group, err := range client.ListActiveSeq("") {
+			count++
+			assert.Equal(t, ListGroup{}, group)
+			assert.ErrorContains(t, err, "unexpected response code: 503")
+		}
+		assert.Equal(t, 1, count)
+	})
+}
+
+func Test_ListActiveTimesSeq(t *testing.T) {
+	server, client := getServerAndClient(t, listHandler)
+	defer server.Close()
+
+	creators := make([]string, 0)
+	for group, err := range client.ListActiveTimesSeq("") {
+		require.Nil(t, err)
+		creators = append(creators, group.Creator)
+	}
+	assert.Equal(t, []string{"<a@example.com>", "b"}, creators)
+}
+
+func Test_ListNewsgroupsSeq(t *testing.T) {
+	server, client := getServerAndClient(t, listHandler)
+	defer server.Close()
+
+	descriptions := make([]string, 0)
+	for group, err := range client.ListNewsgroupsSeq("") {
+		require.Nil(t, err)
+		descriptions = append(descriptions, group.Description)
+	}
+	assert.Equal(t, []string{"First group", "Dot-stuffed"}, descriptions)
+}
@@ -7,9 +7,19 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func Test_listCmd(t *testing.T) {
+func Test_listLines(t *testing.T) {
+	collectLines := func(client *Client) ([]string, error) {
+		var lines []string
+		err := client.listLines("list", func(line string) error {
+			lines = append(lines, line)
+			return nil
+		})
+		return lines, err
+	}
+
 	t.Run("handles bad response", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
 			writeLines(c, "bad response")
@@ -18,8 +28,8 @@ func Test_listCmd(t *testing.T) {
 		server, client := getServerAndClient(t, handler)
 		defer server.Close()
 
-		body, err := client.listCmd("list")
-		assert.Nil(t, body)
+		lines, err := collectLines(client)
+		assert.Nil(t, lines)
 		assert.ErrorContains(t, err, "could not process")
 	})
 
@@ -31,34 +41,58 @@ func Test_listCmd(t *testing.T) {
 		server, client := getServerAndClient(t, handler)
 		defer server.Close()
 
-		body, err := client.listCmd("list")
-		assert.Nil(t, body)
+		lines, err := collectLines(client)
+		assert.Nil(t, lines)
 		assert.Equal(t, true, errors.Is(err, NntpError))
 		assert.ErrorContains(t, err, "unexpected response code: 500 (boom)")
 	})
 
-	t.Run("returns body bytes", func(t *testing.T) {
+	t.Run("returns body lines", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
-			writeLines(c, "215 list", "body line", ".")
+			writeLines(c, "215 list", "body line", "..stuffed", ".")
 		}
 
 		server, client := getServerAndClient(t, handler)
 		defer server.Close()
 
-		body, err := client.listCmd("list")
+		lines, err := collectLines(client)
 		assert.Nil(t, err)
-		assert.Equal(t, "body line\r\n", string(body))
+		assert.Equal(t, []string{"body line", ".stuffed"}, lines)
 	})
 }
 
-func Test_bodyToListGroup(t *testing.T) {
-	input := []byte("a.group 42 1 y\r\nb.group 2 1 n\r\n")
-	expected := map[string]ListGroup{
-		"a.group": {Name: "a.group", Low: 1, High: 42, Status: "y"},
-		"b.group": {Name: "b.group", Low: 1, High: 2, Status: "n"},
+func Test_parseListGroup(t *testing.T) {
+	group, err := parseListGroup("a.group 42 1 y")
+	assert.Nil(t, err)
+	assert.Equal(t, ListGroup{Name: "a.group", Low: 1, High: 42, Status: "y"}, group)
+
+	tests := map[string]string{
+		"":                 "expected 4 fields, found 0",
+		"a.group 42 1":     "expected 4 fields, found 3",
+		"a.group x 1 y":    `invalid high water mark "x"`,
+		"a.group 42 -1 y":  `invalid low water mark "-1"`,
+		"a.group 42 1.5 y": `invalid low water mark "1.5"`,
+	}
+	for line, reason := range tests {
+		_, err := parseListGroup(line)
+		assert.EqualError(t, err, reason, line)
 	}
-	found := bodyToListGroup(input)
-	assert.Equal(t, expected, found)
+}
+
+func Test_ParseErrors(t *testing.T) {
+	one := &ListParseError{Line: 2, Content: "bad", Reason: "expected 4 fields, found 1"}
+	two := &ListParseError{Line: 5, Content: "worse", Reason: "expected 4 fields, found 1"}
+
+	errs := ParseErrors{one}
+	assert.EqualError(t, errs, `malformed list line 2, expected 4 fields, found 1: "bad"`)
+
+	errs = ParseErrors{one, two}
+	assert.EqualError(t, errs, `2 malformed list lines, first: malformed list line 2, expected 4 fields, found 1: "bad"`)
+	assert.Equal(t, true, errors.Is(errs, NntpError))
+
+	var parseError *ListParseError
+	require.Equal(t, true, errors.As(errs, &parseError))
+	assert.Equal(t, one, parseError)
 }
 
 func Test_ListActive(t *testing.T) {
@@ -114,6 +148,112 @@ func Test_ListActive(t *testing.T) {
 	})
 }
 
+// listHandler responds to `LIST ACTIVE`, `LIST ACTIVE.TIMES`, and
+// `LIST NEWSGROUPS` with two groups each, and to `DATE` with a fixed date.
+func listHandler(t *testing.T, c net.Conn, cmd string, params []string) {
+	if cmd == "date" {
+		writeLines(c, "111 20240102030405")
+		return
+	}
+	switch params[0] {
+	case "ACTIVE":
+		writeLines(c, "215 list", "a.group 42 1 y", "b.group 2 1 n", ".")
+	case "ACTIVE.TIMES":
+		writeLines(c, "215 list", "a.group 930445408 <a@example.com>", "b.group 930562309 b", ".")
+	case "NEWSGROUPS":
+		writeLines(c, "215 list", "a.group First group", "..b.group Dot-stuffed", ".")
+	}
+}
+
+func Test_ListActiveFunc(t *testing.T) {
+	t.Run("streams groups", func(t *testing.T) {
+		server, client := getServerAndClient(t, listHandler)
+		defer server.Close()
+
+		var groups []ListGroup
+		err := client.ListActiveFunc("", func(group ListGroup) error {
+			groups = append(groups, group)
+			return nil
+		})
+		assert.Nil(t, err)
+
+		expected := []ListGroup{
+			{Name: "a.group", Low: 1, High: 42, Status: "y"},
+			{Name: "b.group", Low: 1, High: 2, Status: "n"},
+		}
+		assert.Equal(t, expected, groups)
+	})
+
+	t.Run("stops on errors and reads the remaining list", func(t *testing.T) {
+		server, client := getServerAndClient(t, listHandler)
+		defer server.Close()
+
+		stop := errors.New("stop")
+		calls := 0
+		err := client.ListActiveFunc("", func(group ListGroup) error {
+			calls++
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 1, calls)
+
+		date, err := client.Date()
+		require.Nil(t, err)
+		assert.Equal(t, 2024, date.Year())
+	})
+
+	t.Run("handles unexpected response code", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "503 no active file")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		err := client.ListActiveFunc("", func(group ListGroup) error {
+			t.Error("unexpected group")
+			return nil
+		})
+		assert.ErrorContains(t, err, "unexpected response code: 503")
+	})
+}
+
+func Test_ListActiveTimesFunc(t *testing.T) {
+	server, client := getServerAndClient(t, listHandler)
+	defer server.Close()
+
+	var groups []ListGroupTimes
+	err := client.ListActiveTimesFunc("*", func(group ListGroupTimes) error {
+		groups = append(groups, group)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	expected := []ListGroupTimes{
+		{Name: "a.group", Created: time.Unix(930445408, 0).UTC(), Creator: "<a@example.com>"},
+		{Name: "b.group", Created: time.Unix(930562309, 0).UTC(), Creator: "b"},
+	}
+	assert.Equal(t, expected, groups)
+}
+
+func Test_ListNewsgroupsFunc(t *testing.T) {
+	server, client := getServerAndClient(t, listHandler)
+	defer server.Close()
+
+	var groups []ListNewsgroup
+	err := client.ListNewsgroupsFunc("", func(group ListNewsgroup) error {
+		groups = append(groups, group)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	expected := []ListNewsgroup{
+		{Name: "a.group", Description: "First group"},
+		{Name: ".b.group", Description: "Dot-stuffed"},
+	}
+	assert.Equal(t, expected, groups)
+}
+
 func Test_ListActiveTimes(t *testing.T) {
 	t.Run("handles bad response", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
@@ -324,3 +464,371 @@ func Test_ListNewsgroups(t *testing.T) {
 		assert.Equal(t, expected, list)
 	})
 }
+
+func Test_ListCounts(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		assert.Equal(t, []string{"COUNTS", "misc.*"}, params)
+		writeLines(c, "215 list", "misc.test 3002322 3000234 1234 y", "misc.empty 10 11 0 m", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	list, err := client.ListCounts("misc.*")
+	assert.Nil(t, err)
+
+	expected := map[string]ListGroupCount{
+		"misc.test":  {Name: "misc.test", Low: 3000234, High: 3002322, Count: 1234, Status: "y"},
+		"misc.empty": {Name: "misc.empty", Low: 11, High: 10, Count: 0, Status: "m"},
+	}
+	assert.Equal(t, expected, list)
+}
+
+func Test_ListMotd(t *testing.T) {
+	t.Run("returns the message", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			assert.Equal(t, []string{"MOTD"}, params)
+			writeLines(c, "215 motd", "Welcome!", "", "..and goodbye", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		motd, err := client.ListMotd()
+		assert.Nil(t, err)
+		assert.Equal(t, "Welcome!\n\n.and goodbye\n", motd)
+	})
+
+	t.Run("handles unexpected response code", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "503 no motd")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		motd, err := client.ListMotd()
+		assert.Equal(t, "", motd)
+		assert.ErrorContains(t, err, "unexpected response code: 503 (no motd)")
+	})
+}
+
+func Test_ListSubscriptions(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		assert.Equal(t, []string{"SUBSCRIPTIONS"}, params)
+		writeLines(c, "215 list", "news.announce.newusers", "", "misc.test", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	list, err := client.ListSubscriptions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"news.announce.newusers", "misc.test"}, list)
+}
+
+func Test_ListDistributions(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		assert.Equal(t, []string{"DISTRIBUTIONS"}, params)
+		writeLines(c, "215 list", "usa  Local to the United States of America.", "local\tLocal to this site", "world", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	list, err := client.ListDistributions()
+	assert.Nil(t, err)
+
+	expected := []ListDistribution{
+		{Name: "usa", Description: "Local to the United States of America."},
+		{Name: "local", Description: "Local to this site"},
+		{Name: "world", Description: ""},
+	}
+	assert.Equal(t, expected, list)
+}
+
+func Test_ListModerators(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		assert.Equal(t, []string{"MODERATORS"}, params)
+		writeLines(c, "215 list", "foo.bar:announce@example.com", "*:%s@moderators.example.com", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	list, err := client.ListModerators()
+	assert.Nil(t, err)
+
+	expected := []ListModerator{
+		{Pattern: "foo.bar", Address: "announce@example.com"},
+		{Pattern: "*", Address: "%s@moderators.example.com"},
+	}
+	assert.Equal(t, expected, list)
+}
+
+func Test_ListRaw(t *testing.T) {
+	t.Run("returns the lines", func(t *testing.T) {
+		var received [][]string
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			received = append(received, params)
+			writeLines(c, "215 list", "one line", "..two", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		lines, err := client.ListRaw("HEADERS", "MSGID")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"one line", ".two"}, lines)
+
+		_, err = client.ListRaw("EXTENSIONS", "")
+		assert.Nil(t, err)
+		_, err = client.ListRaw("", "")
+		assert.Nil(t, err)
+
+		assert.Equal(t, [][]string{{"HEADERS", "MSGID"}, {"EXTENSIONS"}, {}}, received)
+	})
+
+	t.Run("checks the capabilities", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "capabilities":
+				writeLines(c, "101 capabilities", "VERSION 2", "LIST ACTIVE HEADERS", ".")
+			case "list":
+				writeLines(c, "215 list", ".")
+			}
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+		client, err := NewWithPort(server.Host, server.Port, WithCapabilityChecks())
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		_, err = client.ListRaw("headers", "")
+		assert.Nil(t, err)
+
+		_, err = client.ListRaw("X-VENDOR", "")
+		assert.Equal(t, true, errors.Is(err, ErrNotSupported))
+
+		_, err = client.ListMotd()
+		assert.ErrorContains(t, err, "LIST MOTD: not supported by server")
+	})
+}
+
+func Test_ListMalformedLines(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		if cmd == "newgroups" {
+			writeLines(c, "231 new groups", "a.group 42 1 y", "broken", ".")
+			return
+		}
+		switch params[0] {
+		case "ACTIVE":
+			writeLines(c, "215 list", "a.group 42 1 y", "broken", "b.group high 1 n", "c.group 3 1 m", ".")
+		case "ACTIVE.TIMES":
+			writeLines(c, "215 list", "a.group yesterday someone", "b.group 930562309", ".")
+		case "DISTRIB.PATS":
+			writeLines(c, "215 list", "10:local.*:local", "ten:*:world", "5:*", ".")
+		case "COUNTS":
+			writeLines(c, "215 list", "a.group 42 1 40 y", "b.group 42 1 many y", ".")
+		case "MODERATORS":
+			writeLines(c, "215 list", "no colon", "*:%s@example.com", ".")
+		}
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	t.Run("returns the well-formed groups", func(t *testing.T) {
+		list, err := client.ListActive("")
+		expected := map[string]ListGroup{
+			"a.group": {Name: "a.group", Low: 1, High: 42, Status: "y"},
+			"c.group": {Name: "c.group", Low: 1, High: 3, Status: "m"},
+		}
+		assert.Equal(t, expected, list)
+
+		var parseErrors ParseErrors
+		require.Equal(t, true, errors.As(err, &parseErrors))
+		expectedErrors := ParseErrors{
+			{Line: 2, Content: "broken", Reason: "expected 4 fields, found 1"},
+			{Line: 3, Content: "b.group high 1 n", Reason: `invalid high water mark "high"`},
+		}
+		assert.Equal(t, expectedErrors, parseErrors)
+	})
+
+	t.Run("invokes the callback for well-formed groups", func(t *testing.T) {
+		var names []string
+		err := client.ListActiveFunc("", func(group ListGroup) error {
+			names = append(names, group.Name)
+			return nil
+		})
+		assert.Equal(t, []string{"a.group", "c.group"}, names)
+		assert.Equal(t, true, isParseErrors(err))
+	})
+
+	t.Run("handles the other lists", func(t *testing.T) {
+		times, err := client.ListActiveTimes("")
+		assert.Equal(t, map[string]ListGroupTimes{
+			"b.group": {Name: "b.group", Created: time.Unix(930562309, 0).UTC()},
+		}, times)
+		assert.ErrorContains(t, err, `invalid creation time "yesterday"`)
+
+		patterns, err := client.ListDistribPats()
+		assert.Equal(t, []ListDistribPattern{{Weight: 10, Wildmat: "local.*", Value: "local"}}, patterns)
+		assert.ErrorContains(t, err, "2 malformed list lines")
+
+		counts, err := client.ListCounts("")
+		assert.Len(t, counts, 1)
+		assert.ErrorContains(t, err, `invalid article count "many"`)
+
+		moderators, err := client.ListModerators()
+		assert.Equal(t, []ListModerator{{Pattern: "*", Address: "%s@example.com"}}, moderators)
+		assert.ErrorContains(t, err, "expected pattern and address separated by a colon")
+
+		groups, err := client.NewGroups(time.Now().UTC())
+		assert.Len(t, groups, 1)
+		assert.Equal(t, true, isParseErrors(err))
+	})
+}
+
+// fuzzListParser checks that parse does not panic, and that it either
+// returns an error or an entry that passes check.
+func fuzzListParser[T any](f *testing.F, parse func(string) (T, error), check func(*testing.T, T), seeds ...string) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		item, err := parse(line)
+		if err == nil {
+			check(t, item)
+		}
+	})
+}
+
+func Fuzz_parseListGroup(f *testing.F) {
+	fuzzListParser(f, parseListGroup, func(t *testing.T, group ListGroup) {
+		assert.NotEmpty(t, group.Name)
+		assert.GreaterOrEqual(t, group.Low, 0)
+		assert.GreaterOrEqual(t, group.High, 0)
+		assert.NotEmpty(t, group.Status)
+	}, "a.group 42 1 y", "a.group 42 1", "a 1 2 =b.group", " \t ", "a -1 2 y")
+}
+
+func Fuzz_parseListGroupTimes(f *testing.F) {
+	fuzzListParser(f, parseListGroupTimes, func(t *testing.T, group ListGroupTimes) {
+		assert.NotEmpty(t, group.Name)
+	}, "misc.test 930445408 <creatme@isc.org>", "misc.test 930445408", "misc.test x y", "")
+}
+
+func Fuzz_parseListDistribPattern(f *testing.F) {
+	fuzzListParser(f, parseListDistribPattern, func(t *testing.T, pattern ListDistribPattern) {}, "10:local.*:local", "10:*", "x:y:z", ":::")
+}
+
+func Fuzz_parseListNewsgroup(f *testing.F) {
+	fuzzListParser(f, parseListNewsgroup, func(t *testing.T, group ListNewsgroup) {
+		assert.NotEmpty(t, group.Name)
+	}, "misc.test General Usenet testing", "misc.test", "misc.test\tTabbed", "  ")
+}
+
+func Fuzz_parseListGroupCount(f *testing.F) {
+	fuzzListParser(f, parseListGroupCount, func(t *testing.T, group ListGroupCount) {
+		assert.NotEmpty(t, group.Name)
+		assert.GreaterOrEqual(t, group.Count, 0)
+	}, "misc.test 3002322 3000234 1234 y", "misc.test 1 2 3", "a b c d e")
+}
+
+func Fuzz_parseListDistribution(f *testing.F) {
+	fuzzListParser(f, parseListDistribution, func(t *testing.T, distribution ListDistribution) {
+		assert.NotEmpty(t, distribution.Name)
+	}, "usa Local to the United States of America.", "world", "")
+}
+
+func Fuzz_parseListModerator(f *testing.F) {
+	fuzzListParser(f, parseListModerator, func(t *testing.T, moderator ListModerator) {
+		assert.NotEmpty(t, moderator.Pattern)
+		assert.NotEmpty(t, moderator.Address)
+	}, "foo.bar:announce@example.com", "*:%s@example.com", "no colon", ":")
+}
+
+func Test_ListActiveStatus(t *testing.T) {
+	handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+		writeLines(c, "215 list", "comp.lang.go 42 1 y", "comp.golang 0 1 =comp.lang.go", "news.announce 2 1 n", ".")
+	}
+
+	server, client := getServerAndClient(t, handler)
+	defer server.Close()
+
+	list, err := client.ListActive("")
+	require.Nil(t, err)
+
+	assert.Equal(t, StatusPostingPermitted, list["comp.lang.go"].Status)
+	assert.Equal(t, true, list["news.announce"].Status.PostingProhibited())
+	alias, found := list["comp.golang"].Status.AliasOf()
+	assert.Equal(t, true, found)
+	assert.Equal(t, "comp.lang.go", alias)
+}
+
+func Test_ListNewsgroupsFallback(t *testing.T) {
+	t.Run("falls back on unknown commands", func(t *testing.T) {
+		var commands []string
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			commands = append(commands, cmd)
+			switch cmd {
+			case "list":
+				writeLines(c, "501 unknown keyword")
+			case "xgtitle":
+				assert.Equal(t, []string{"misc.*"}, params)
+				writeLines(c, "282 list follows", "misc.test General Usenet testing", ".")
+			}
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		list, err := client.ListNewsgroups("misc.*")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]ListNewsgroup{
+			"misc.test": {Name: "misc.test", Description: "General Usenet testing"},
+		}, list)
+		assert.Equal(t, []string{"list", "xgtitle"}, commands)
+	})
+
+	t.Run("falls back when not advertised", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			switch cmd {
+			case "capabilities":
+				writeLines(c, "101 capabilities", "VERSION 2", "READER", "LIST ACTIVE", ".")
+			case "xgtitle":
+				writeLines(c, "282 list follows", "misc.test General Usenet testing", ".")
+			default:
+				t.Errorf("unexpected command %s", cmd)
+			}
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+		client, err := NewWithPort(server.Host, server.Port, WithCapabilityChecks())
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		list, err := client.ListNewsgroups("")
+		assert.Nil(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("does not fall back on other errors", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			assert.Equal(t, "list", cmd)
+			writeLines(c, "503 unavailable")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		list, err := client.ListNewsgroups("")
+		assert.Nil(t, list)
+		assert.ErrorContains(t, err, "unexpected response code: 503")
+	})
+}
@@ -0,0 +1,59 @@
+package mimewalk
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to Unicode. All
+// other bytes map to the code point of the same value. Undefined bytes are
+// mapped to the corresponding C1 control character.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+func decodeWindows1252(content []byte) []byte {
+	result := make([]byte, 0, len(content))
+	for _, b := range content {
+		switch {
+		case b < 0x80:
+			result = append(result, b)
+		case b < 0xA0:
+			result = utf8.AppendRune(result, windows1252[b-0x80])
+		default:
+			result = utf8.AppendRune(result, rune(b))
+		}
+	}
+	return result
+}
+
+// base64Filter drops everything but the characters of the standard base64
+// alphabet, e.g. line breaks and trailing whitespace, which are common in
+// articles but rejected by [base64.NewDecoder].
+type base64Filter struct {
+	reader io.Reader
+}
+
+func (f *base64Filter) Read(buf []byte) (int, error) {
+	for {
+		n, err := f.reader.Read(buf)
+		kept := 0
+		for _, b := range buf[:n] {
+			if isBase64(b) {
+				buf[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+func isBase64(b byte) bool {
+	return 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' ||
+		b == '+' || b == '/' || b == '='
+}
@@ -0,0 +1,301 @@
+// Package mimewalk walks the parts of MIME formatted articles, e.g. those
+// retrieved with [nntpclient.Client.FetchArticle], and yields the content of
+// every part with its transfer encoding removed and, for text parts, its
+// charset converted to UTF-8:
+//
+//	article, err := client.FetchArticle("<foo@example.com>")
+//	// ...
+//	err = mimewalk.Walk(article.Headers, article.Body, func(part *mimewalk.Part) error {
+//		fmt.Println(part.MediaType, part.Filename, len(part.Content))
+//		return nil
+//	})
+//
+// Articles without MIME headers are treated as a single `text/plain` part.
+package mimewalk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDepth limits the nesting of multipart and message parts.
+const maxDepth = 16
+
+// ErrUnsupportedCharset is set as [Part.Err] when the content of a text part
+// uses a charset that cannot be converted to UTF-8.
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+// Part is a single, non-multipart, part of an article.
+type Part struct {
+	// Header contains the headers of the part. For an article without a
+	// multipart body, these are the headers of the article.
+	Header textproto.MIMEHeader
+	// MediaType is the lower case media type of the part, e.g. `text/plain`.
+	MediaType string
+	// Params are the parameters of the `Content-Type` header.
+	Params map[string]string
+	// Disposition is the lower case disposition of the part, e.g.
+	// `attachment`, or an empty string if none is given.
+	Disposition string
+	// Filename is the decoded name of the file carried by the part, if any.
+	Filename string
+	// Charset is the lower case charset declared for a text part.
+	Charset string
+	// Content is the content of the part. The transfer encoding has been
+	// removed and, for text parts, the charset has been converted to UTF-8.
+	Content []byte
+	// Err is set if the content could not be decoded. In that case, Content
+	// holds the content as far as it could be decoded.
+	Err error
+}
+
+// IsText indicates if the part has a `text/*` media type.
+func (p *Part) IsText() bool {
+	return strings.HasPrefix(p.MediaType, "text/")
+}
+
+// IsAttachment indicates if the part is meant to be saved instead of being
+// displayed, i.e. it has an `attachment` disposition or a filename.
+func (p *Part) IsAttachment() bool {
+	return p.Disposition == "attachment" || p.Filename != ""
+}
+
+// WalkFunc is invoked for each part. If it returns an error, the walk stops
+// and the error is returned by [Walk].
+type WalkFunc func(part *Part) error
+
+// Walker walks articles. The zero value is ready to use.
+type Walker struct {
+	// CharsetReader, if not nil, is consulted for charsets other than the
+	// built-in UTF-8, US-ASCII, ISO-8859-1, and Windows-1252. It must return
+	// a reader that converts input to UTF-8. The signature matches the one of
+	// [mime.WordDecoder], so that, for example, the readers provided by
+	// `golang.org/x/net/html/charset` can be used.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+}
+
+// Walk walks the article described by header and body with a zero [Walker].
+func Walk(header textproto.MIMEHeader, body []byte, fn WalkFunc) error {
+	return (&Walker{}).Walk(header, body, fn)
+}
+
+// Parts collects all parts of the article with a zero [Walker].
+func Parts(header textproto.MIMEHeader, body []byte) ([]*Part, error) {
+	var parts []*Part
+	err := Walk(header, body, func(part *Part) error {
+		parts = append(parts, part)
+		return nil
+	})
+	return parts, err
+}
+
+// Walk invokes fn for each part of the article described by header and body,
+// in the order they appear in the article. Multipart bodies are descended
+// into, as are attached messages (`message/rfc822`). The containers themselves
+// are not passed to fn.
+//
+// The body must not be dot-stuffed, as are bodies returned by
+// [nntpclient.Client.Article] and [nntpclient.Client.Body]. Bodies returned by
+// [nntpclient.Client.FetchArticle] have the stuffing removed.
+//
+// Problems decoding the content of a single part are reported via [Part.Err].
+// An error is only returned if the structure of the article cannot be
+// processed, or if fn returns an error.
+func (w *Walker) Walk(header textproto.MIMEHeader, body []byte, fn WalkFunc) error {
+	return w.walk(header, body, fn, 0)
+}
+
+func (w *Walker) walk(header textproto.MIMEHeader, body []byte, fn WalkFunc, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("mimewalk: parts nested deeper than %d levels", maxDepth)
+	}
+
+	mediaType, params := parseContentType(header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "":
+		return w.walkMultipart(body, params["boundary"], fn, depth)
+	case mediaType == "message/rfc822":
+		return w.walkMessage(header, body, fn, depth)
+	}
+
+	return fn(w.newPart(header, mediaType, params, body))
+}
+
+func (w *Walker) walkMultipart(body []byte, boundary string, fn WalkFunc, depth int) error {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		// Raw parts retain their Content-Transfer-Encoding header, which is
+		// decoded by newPart for all parts alike.
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("mimewalk: %w", err)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("mimewalk: %w", err)
+		}
+		err = w.walk(part.Header, content, fn, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Walker) walkMessage(header textproto.MIMEHeader, body []byte, fn WalkFunc, depth int) error {
+	content, err := decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+	if err != nil {
+		return fmt.Errorf("mimewalk: attached message: %w", err)
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(content)))
+	messageHeader, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("mimewalk: attached message: %w", err)
+	}
+	messageBody, err := io.ReadAll(reader.R)
+	if err != nil {
+		return fmt.Errorf("mimewalk: attached message: %w", err)
+	}
+	return w.walk(messageHeader, messageBody, fn, depth+1)
+}
+
+func (w *Walker) newPart(header textproto.MIMEHeader, mediaType string, params map[string]string, body []byte) *Part {
+	part := &Part{
+		Header:    header,
+		MediaType: mediaType,
+		Params:    params,
+	}
+
+	disposition, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if err == nil {
+		part.Disposition = disposition
+	}
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	part.Filename = w.decodeWords(filename)
+
+	part.Content, part.Err = decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+	if part.IsText() {
+		part.Charset = strings.ToLower(params["charset"])
+		if part.Charset == "" {
+			part.Charset = "us-ascii"
+		}
+
+		var content []byte
+		content, err = w.convert(part.Charset, part.Content)
+		if err == nil {
+			part.Content = content
+		} else if part.Err == nil {
+			part.Err = err
+		}
+	}
+
+	return part
+}
+
+// parseContentType parses the value of a `Content-Type` header. Missing or
+// invalid values are treated as `text/plain` (RFC 2045 §5.2).
+func parseContentType(value string) (string, map[string]string) {
+	if value == "" {
+		return "text/plain", map[string]string{}
+	}
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil && !errors.Is(err, mime.ErrInvalidMediaParameter) || !strings.Contains(mediaType, "/") {
+		return "text/plain", map[string]string{}
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	return mediaType, params
+}
+
+// decodeTransfer removes the content transfer encoding. Unknown encodings are
+// left as is. If decoding fails, the content decoded so far is returned along
+// with the error.
+func decodeTransfer(encoding string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		reader = base64.NewDecoder(base64.StdEncoding, &base64Filter{bytes.NewReader(body)})
+	case "quoted-printable":
+		reader = quotedprintable.NewReader(bytes.NewReader(body))
+	default:
+		return body, nil
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return content, fmt.Errorf("mimewalk: %s: %w", strings.ToLower(encoding), err)
+	}
+	return content, nil
+}
+
+// decodeWords decodes RFC 2047 encoded words, e.g. in filenames. The value is
+// returned as is if it cannot be decoded.
+func (w *Walker) decodeWords(value string) string {
+	decoder := mime.WordDecoder{CharsetReader: w.charsetReader}
+	decoded, err := decoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// charsetReader adapts the built-in conversions, and [Walker.CharsetReader],
+// to the signature expected by [mime.WordDecoder].
+func (w *Walker) charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := w.convert(strings.ToLower(charset), content)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(converted), nil
+}
+
+// convert converts content from the given lower case charset to UTF-8.
+func (w *Walker) convert(charset string, content []byte) ([]byte, error) {
+	switch charset {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		// Eight bit data is often found in parts declared as US-ASCII. UTF-8
+		// is the most likely intent.
+		return bytes.ToValidUTF8(content, []byte(string(utf8.RuneError))), nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1",
+		"windows-1252", "cp1252", "x-cp1252":
+		// As is common practice, ISO-8859-1 is treated as its superset
+		// Windows-1252.
+		return decodeWindows1252(content), nil
+	}
+
+	if w.CharsetReader != nil {
+		reader, err := w.CharsetReader(charset, bytes.NewReader(content))
+		if err != nil {
+			return content, fmt.Errorf("mimewalk: %s: %w", charset, err)
+		}
+		converted, err := io.ReadAll(reader)
+		if err != nil {
+			return content, fmt.Errorf("mimewalk: %s: %w", charset, err)
+		}
+		return converted, nil
+	}
+
+	return content, fmt.Errorf("mimewalk: %s: %w", charset, ErrUnsupportedCharset)
+}
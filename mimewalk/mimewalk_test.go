@@ -0,0 +1,210 @@
+package mimewalk
+
+import (
+	"errors"
+	"io"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crlf(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func Test_Walk(t *testing.T) {
+	t.Run("handles plain articles", func(t *testing.T) {
+		parts, err := Parts(textproto.MIMEHeader{}, crlf("hello"))
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+
+		assert.Equal(t, "text/plain", parts[0].MediaType)
+		assert.Equal(t, "us-ascii", parts[0].Charset)
+		assert.Equal(t, "hello\r\n", string(parts[0].Content))
+		assert.Nil(t, parts[0].Err)
+		assert.Equal(t, true, parts[0].IsText())
+		assert.Equal(t, false, parts[0].IsAttachment())
+	})
+
+	t.Run("decodes transfer encodings and charsets", func(t *testing.T) {
+		header := textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=ISO-8859-1"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}
+		parts, err := Parts(header, crlf("caf=E9 =80 long=", "line"))
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+
+		assert.Equal(t, "iso-8859-1", parts[0].Charset)
+		assert.Equal(t, "café € longline\r\n", string(parts[0].Content))
+	})
+
+	t.Run("retains lines starting with a dot", func(t *testing.T) {
+		header := textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}
+		parts, err := Parts(header, crlf(".hidden", "...", "=2E"))
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+
+		assert.Equal(t, ".hidden\r\n...\r\n.\r\n", string(parts[0].Content))
+	})
+
+	t.Run("walks multipart articles", func(t *testing.T) {
+		header := textproto.MIMEHeader{
+			"Mime-Version": {"1.0"},
+			"Content-Type": {`multipart/mixed; boundary="outer"`},
+		}
+		body := crlf(
+			"This is a multi-part message in MIME format.",
+			"--outer",
+			"Content-Type: multipart/alternative; boundary=inner",
+			"",
+			"--inner",
+			"Content-Type: text/plain; charset=utf-8",
+			"Content-Transfer-Encoding: 8bit",
+			"",
+			"plain caf\xc3\xa9",
+			"--inner",
+			"Content-Type: text/html; charset=windows-1252",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"PHA+k3F1b3Rlc5Q8L3A+",
+			"--inner--",
+			"--outer",
+			"Content-Type: application/octet-stream; name=\"ignored.bin\"",
+			"Content-Disposition: attachment; filename=\"=?UTF-8?Q?d=C3=A4ta?=.bin\"",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"AAEC",
+			"/w== ",
+			"--outer",
+			"Content-Type: message/rfc822",
+			"",
+			"Subject: attached",
+			"Content-Type: text/plain; charset=us-ascii",
+			"",
+			"attached body",
+			"--outer--",
+		)
+
+		parts, err := Parts(header, body)
+		require.Nil(t, err)
+		require.Len(t, parts, 4)
+
+		assert.Equal(t, "text/plain", parts[0].MediaType)
+		assert.Equal(t, "plain café", string(parts[0].Content))
+
+		assert.Equal(t, "text/html", parts[1].MediaType)
+		assert.Equal(t, "windows-1252", parts[1].Charset)
+		assert.Equal(t, "<p>“quotes”</p>", string(parts[1].Content))
+
+		assert.Equal(t, "application/octet-stream", parts[2].MediaType)
+		assert.Equal(t, "attachment", parts[2].Disposition)
+		assert.Equal(t, "däta.bin", parts[2].Filename)
+		assert.Equal(t, []byte{0, 1, 2, 0xff}, parts[2].Content)
+		assert.Equal(t, "", parts[2].Charset)
+		assert.Equal(t, true, parts[2].IsAttachment())
+
+		assert.Equal(t, "attached", parts[3].Header.Get("Subject"))
+		// The line break preceding a boundary belongs to the boundary.
+		assert.Equal(t, "attached body", string(parts[3].Content))
+	})
+
+	t.Run("reports undecodable parts", func(t *testing.T) {
+		header := textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=x-unknown"},
+			"Content-Transfer-Encoding": {"base64"},
+		}
+		parts, err := Parts(header, crlf("aGk="))
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+
+		assert.Equal(t, "hi", string(parts[0].Content))
+		assert.Equal(t, true, errors.Is(parts[0].Err, ErrUnsupportedCharset))
+
+		header.Set("Content-Type", "application/octet-stream")
+		parts, err = Parts(header, crlf("aGk=aGk="))
+		require.Nil(t, err)
+		assert.ErrorContains(t, parts[0].Err, "mimewalk: base64")
+	})
+
+	t.Run("treats invalid content types as text", func(t *testing.T) {
+		header := textproto.MIMEHeader{"Content-Type": {"bogus"}}
+		parts, err := Parts(header, crlf("\xffhi"))
+		require.Nil(t, err)
+		assert.Equal(t, "text/plain", parts[0].MediaType)
+		assert.Equal(t, "�hi\r\n", string(parts[0].Content))
+	})
+
+	t.Run("returns structural errors", func(t *testing.T) {
+		header := textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=b"}}
+		_, err := Parts(header, crlf("--b", "Broken header", "", "x", "--b--"))
+		assert.ErrorContains(t, err, "mimewalk: ")
+	})
+
+	t.Run("limits nesting", func(t *testing.T) {
+		header := textproto.MIMEHeader{"Content-Type": {"message/rfc822"}}
+		body := crlf("Content-Type: text/plain", "", "innermost")
+		for i := 0; i < maxDepth+1; i++ {
+			body = append(crlf("Content-Type: message/rfc822", ""), body...)
+		}
+		_, err := Parts(header, body)
+		assert.ErrorContains(t, err, "nested deeper than")
+	})
+
+	t.Run("stops when the callback fails", func(t *testing.T) {
+		header := textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=b"}}
+		body := crlf("--b", "", "one", "--b", "", "two", "--b--")
+
+		boom := errors.New("boom")
+		count := 0
+		err := Walk(header, body, func(part *Part) error {
+			count++
+			return boom
+		})
+		assert.Equal(t, boom, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func Test_CharsetReader(t *testing.T) {
+	walker := &Walker{
+		CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+			if charset != "x-upper" {
+				return nil, errors.New("nope")
+			}
+			content, _ := io.ReadAll(input)
+			return strings.NewReader(strings.ToUpper(string(content))), nil
+		},
+	}
+
+	header := textproto.MIMEHeader{
+		"Content-Type":        {"text/plain; charset=X-Upper"},
+		"Content-Disposition": {`inline; filename="=?x-upper?q?name?="`},
+	}
+	var parts []*Part
+	err := walker.Walk(header, crlf("shout"), func(part *Part) error {
+		parts = append(parts, part)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "SHOUT\r\n", string(parts[0].Content))
+	assert.Equal(t, "NAME", parts[0].Filename)
+
+	header.Set("Content-Type", "text/plain; charset=x-other")
+	err = walker.Walk(header, crlf("quiet"), func(part *Part) error {
+		assert.Equal(t, "quiet\r\n", string(part.Content))
+		assert.ErrorContains(t, part.Err, "mimewalk: x-other: nope")
+		return nil
+	})
+	require.Nil(t, err)
+}
+
+func Test_decodeWindows1252(t *testing.T) {
+	assert.Equal(t, "a€\u0081ÿ", string(decodeWindows1252([]byte{'a', 0x80, 0x81, 0xff})))
+}
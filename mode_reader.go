@@ -1,7 +1,30 @@
 package nntpclient
 
+// WithModeReaderNegotiation enables switching mode-switching servers to
+// reader mode when connecting. After the server greeting has been received,
+// the server capabilities are retrieved and, only if `MODE-READER` is
+// advertised, `MODE READER` is issued. The capabilities are refreshed
+// afterward, as the server is expected to advertise different capabilities
+// once in reader mode.
+//
+// Servers that do not support `CAPABILITIES` are left in whatever mode they
+// started in, as issuing `MODE READER` may be harmful on some servers, e.g.
+// after authentication.
+func WithModeReaderNegotiation() Option {
+	return func(client *Client) {
+		client.negotiateModeReader = true
+	}
+}
+
 // ModeReader toggles the connection mode to "reader".
 func (c *Client) ModeReader() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.modeReader()
+}
+
+func (c *Client) modeReader() error {
 	code, message, err := c.sendCommand("MODE READER")
 	if err != nil {
 		return err
@@ -18,6 +41,33 @@ func (c *Client) ModeReader() error {
 	default:
 		return UnexpectedError(code, message)
 	}
+	c.invalidateCapabilities()
 
 	return nil
 }
+
+// negotiateReaderMode issues `MODE READER` if, and only if, the server
+// advertises the `MODE-READER` capability. See [WithModeReaderNegotiation].
+func (c *Client) negotiateReaderMode() error {
+	caps, err := c.capabilities()
+	if err != nil {
+		if c.capabilitiesUnknown {
+			return nil
+		}
+		return err
+	}
+	if !caps.HasModeReader() {
+		return nil
+	}
+
+	c.logger.Debug("switching to reader mode")
+	if err := c.modeReader(); err != nil {
+		return err
+	}
+
+	_, err = c.capabilities()
+	if err != nil && !c.capabilitiesUnknown {
+		return err
+	}
+	return nil
+}
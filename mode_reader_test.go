@@ -3,9 +3,12 @@ package nntpclient
 import (
 	"errors"
 	"net"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_ModeReader(t *testing.T) {
@@ -71,3 +74,100 @@ func Test_ModeReader(t *testing.T) {
 		assert.Equal(t, false, client.CanPost)
 	})
 }
+
+func Test_ModeReaderNegotiation(t *testing.T) {
+	// recordingServer starts a server that records the received commands. The
+	// capabilities advertised depend on whether reader mode has been entered.
+	recordingServer := func(t *testing.T, before []string, after []string, modeResponse string) (*TestServer, func() []string) {
+		var mu sync.Mutex
+		var commands []string
+		readerMode := false
+
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands = append(commands, strings.TrimSpace(cmd+" "+strings.Join(params, " ")))
+
+			switch cmd {
+			case "capabilities":
+				caps := before
+				if readerMode {
+					caps = after
+				}
+				if caps == nil {
+					writeLines(c, "500 unknown command")
+					return
+				}
+				writeLines(c, "101 capabilities")
+				writeLines(c, caps...)
+				writeLines(c, ".")
+			case "mode":
+				readerMode = true
+				writeLines(c, modeResponse)
+			}
+		}
+
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+
+		return server, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return commands
+		}
+	}
+
+	connect := func(server *TestServer) (*Client, error) {
+		client, err := NewWithPort(server.Host, server.Port, WithModeReaderNegotiation())
+		require.Nil(t, err)
+		return client, client.Connect()
+	}
+
+	t.Run("switches mode-switching servers", func(t *testing.T) {
+		server, commands := recordingServer(
+			t,
+			[]string{"VERSION 2", "IHAVE", "MODE-READER"},
+			[]string{"VERSION 2", "READER", "LIST ACTIVE"},
+			"201 reader, no posting",
+		)
+		defer server.Close()
+
+		client, err := connect(server)
+		require.Nil(t, err)
+		assert.Equal(t, false, client.CanPost)
+
+		caps, err := client.Capabilities()
+		require.Nil(t, err)
+		assert.Equal(t, true, caps.HasReader())
+		assert.Equal(t, []string{"capabilities", "mode READER", "capabilities"}, commands())
+	})
+
+	t.Run("leaves reader servers alone", func(t *testing.T) {
+		server, commands := recordingServer(t, []string{"VERSION 2", "READER"}, nil, "")
+		defer server.Close()
+
+		client, err := connect(server)
+		require.Nil(t, err)
+		assert.Equal(t, true, client.CanPost)
+		assert.Equal(t, []string{"capabilities"}, commands())
+	})
+
+	t.Run("leaves servers without capabilities alone", func(t *testing.T) {
+		server, commands := recordingServer(t, nil, nil, "")
+		defer server.Close()
+
+		client, err := connect(server)
+		require.Nil(t, err)
+		assert.Equal(t, StateConnected, client.State())
+		assert.Equal(t, []string{"capabilities"}, commands())
+	})
+
+	t.Run("fails to connect when mode reader fails", func(t *testing.T) {
+		server, _ := recordingServer(t, []string{"VERSION 2", "MODE-READER"}, nil, "502 no reading")
+		defer server.Close()
+
+		client, err := connect(server)
+		assert.Equal(t, true, errors.Is(err, ErrReadingUnavailable))
+		assert.Equal(t, StateNew, client.State())
+	})
+}
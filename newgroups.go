@@ -1,22 +1,19 @@
 package nntpclient
 
-import (
-	"bytes"
-	"time"
-)
+import "time"
 
 // NewGroups queries the server for the list of groups that have been added
-// since a given time. If the location of that time is _not_ UTC, then only
-// the date and time fields will be sent, leaving the interpretation of that
-// time up to the server. If the location is set to UTC, then the GMT parameter
-// is included in the query to the server.
+// since a given time. The time is sent as GMT, see [DateArgument]. Malformed
+// lines are reported as described for [Client.ListActive].
 func (c *Client) NewGroups(since time.Time) (map[string]ListGroup, error) {
-	strTime := since.Format("20060102 150405")
-	if since.Location() == time.UTC {
-		strTime = strTime + " GMT"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
 	}
 
-	code, message, err := c.sendCommand("NEWGROUPS " + strTime)
+	code, message, err := c.sendCommand("NEWGROUPS " + c.dateArgument(since))
 	if err != nil {
 		return nil, err
 	}
@@ -24,12 +21,10 @@ func (c *Client) NewGroups(since time.Time) (map[string]ListGroup, error) {
 		return nil, UnexpectedError(code, message)
 	}
 
-	var body bytes.Buffer
-	err = c.readBody(&body)
-	if err != nil {
+	result := make(map[string]ListGroup)
+	err = readParsed(c, parseListGroup, collectByName(result, func(group ListGroup) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
 		return nil, err
 	}
-
-	result := bodyToListGroup(body.Bytes())
-	return result, nil
+	return result, err
 }
@@ -10,7 +10,7 @@ import (
 
 func Test_NewGroups(t *testing.T) {
 	// 2023-11-12T08:00:00.000-05:00
-	baseDate := time.Unix(1699794000, 0)
+	baseDate := time.Unix(1699794000, 0).In(time.FixedZone("EST", -5*60*60))
 
 	t.Run("handles bad response", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
@@ -52,10 +52,10 @@ func Test_NewGroups(t *testing.T) {
 		assert.ErrorContains(t, err, "unexpected end of response:")
 	})
 
-	t.Run("returns list (ambiguous time)", func(t *testing.T) {
+	t.Run("returns list (local time)", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
 			assert.Equal(t, "newgroups", cmd)
-			assert.Equal(t, []string{"20231112", "080000"}, params)
+			assert.Equal(t, []string{"20231112", "130000", "GMT"}, params)
 			writeLines(c, "231 groups", "alt.rfc-writers.recovery 4 1 y", ".")
 		}
 
@@ -1,46 +1,153 @@
 package nntpclient
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
+// maxCommandLength is the maximum length of a command line, excluding the
+// terminating CRLF. See RFC 3977 §3.1.
+const maxCommandLength = 510
+
+// NewNewsQuery describes a `NEWNEWS` query for [Client.QueryNewNews].
+type NewNewsQuery struct {
+	// Wildmats are the patterns that select the groups, as in a single
+	// wildmat. Later patterns take precedence, e.g. `comp.*`, `!comp.os.*`
+	// selects all groups in the comp hierarchy except those in comp.os.
+	// Elements may also contain complete, comma-separated, wildmats.
+	Wildmats []string
+	// Since is the time from which articles are listed. It is sent as GMT,
+	// see [DateArgument].
+	Since time.Time
+	// Distributions restricts the articles to those with one of the given
+	// `Distribution` headers. This is an RFC 977 feature that is ignored, or
+	// rejected, by most current servers.
+	Distributions []string
+	// MaxLength is the maximum length of a command. Queries whose patterns do
+	// not fit into a single command are split into several commands. It
+	// defaults to 510, the limit of RFC 3977 §3.1.
+	MaxLength int
+}
+
 // NewNews queries the server for a list of new articles in groups matching
-// the given wildmat since the given time. As with [NewGroups], the GMT
-// parameter is dependent upon the since time being set to UTC.
+// the given wildmat since the given time. The time is sent as GMT, see
+// [DateArgument].
 func (c *Client) NewNews(wildmat string, since time.Time) ([]string, error) {
-	if wildmat == "" {
-		return nil, errors.New("wildmat cannot be empty")
+	return c.QueryNewNews(NewNewsQuery{Wildmats: []string{wildmat}, Since: since})
+}
+
+// QueryNewNews queries the server for the message-ids of new articles as
+// described by the query. If the patterns are too long for a single command,
+// they are split into several commands, and the message-ids are combined
+// without duplicates, in the order they were first returned. If all patterns
+// are negated, no group matches, and an empty list is returned without
+// querying the server.
+func (c *Client) QueryNewNews(query NewNewsQuery) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("NEWNEWS"); err != nil {
+		return nil, err
 	}
 
-	strTime := since.Format("20060102 150405")
-	if since.Location() == time.UTC {
-		strTime = strTime + " GMT"
+	var patterns []string
+	for _, wildmat := range query.Wildmats {
+		for _, pattern := range strings.Split(wildmat, ",") {
+			if pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("wildmat cannot be empty")
 	}
 
-	code, message, err := c.sendCommand(fmt.Sprintf("NEWNEWS %s %s", wildmat, strTime))
-	if err != nil {
-		return nil, err
+	suffix := " " + c.dateArgument(query.Since)
+	if len(query.Distributions) > 0 {
+		suffix += " <" + strings.Join(query.Distributions, ",") + ">"
 	}
-	if code != 230 {
-		return nil, UnexpectedError(code, message)
+	maxLength := query.MaxLength
+	if maxLength <= 0 {
+		maxLength = maxCommandLength
 	}
 
-	var body bytes.Buffer
-	err = c.readBody(&body)
+	wildmats, err := splitWildmat(patterns, maxLength-len("NEWNEWS ")-len(suffix))
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]string, 0)
-	scanner := bufio.NewScanner(bytes.NewReader(body.Bytes()))
-	for scanner.Scan() {
-		line := scanner.Text()
-		result = append(result, line)
+	seen := make(map[string]bool)
+	for _, wildmat := range wildmats {
+		code, message, err := c.sendCommand("NEWNEWS " + wildmat + suffix)
+		if err != nil {
+			return nil, err
+		}
+		if code != 230 {
+			return nil, UnexpectedError(code, message)
+		}
+
+		err = c.readLines(func(line string) error {
+			if line != "" && !seen[line] {
+				seen[line] = true
+				result = append(result, line)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// splitWildmat combines the patterns into wildmats of at most maxLength
+// characters that together match the same groups as the complete wildmat.
+// Each wildmat holds some of the positive patterns, along with all negated
+// patterns that follow the first of them, so that the last-match-wins
+// semantics are preserved. Negated patterns that precede all positive
+// patterns have no effect and are dropped. If there are no positive
+// patterns, no group matches and no wildmats are returned.
+func splitWildmat(patterns []string, maxLength int) ([]string, error) {
+	// build returns the wildmat for the positive patterns from index first
+	// to index last.
+	build := func(first int, last int) string {
+		var selected []string
+		for i := first; i < len(patterns); i++ {
+			negated := strings.HasPrefix(patterns[i], "!")
+			if negated || i <= last {
+				selected = append(selected, patterns[i])
+			}
+		}
+		return strings.Join(selected, ",")
 	}
 
+	var result []string
+	first := -1
+	current := ""
+	for i, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		if first >= 0 {
+			if candidate := build(first, i); len(candidate) <= maxLength {
+				current = candidate
+				continue
+			}
+			result = append(result, current)
+		}
+
+		first = i
+		current = build(i, i)
+		if len(current) > maxLength {
+			return nil, fmt.Errorf("wildmat is too long for a single command: %q", current)
+		}
+	}
+	if first >= 0 {
+		result = append(result, current)
+	}
 	return result, nil
 }
@@ -2,15 +2,18 @@ package nntpclient
 
 import (
 	"net"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/popnzb/nntpclient/wildmat"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_NewNews(t *testing.T) {
 	// 2023-11-12T08:00:00.000-05:00
-	baseDate := time.Unix(1699794000, 0)
+	baseDate := time.Unix(1699794000, 0).In(time.FixedZone("EST", -5*60*60))
 
 	t.Run("returns error for empty wildmat", func(t *testing.T) {
 		client := Client{}
@@ -59,10 +62,10 @@ func Test_NewNews(t *testing.T) {
 		assert.ErrorContains(t, err, "unexpected end of response")
 	})
 
-	t.Run("returns list (ambiguous time)", func(t *testing.T) {
+	t.Run("returns list (local time)", func(t *testing.T) {
 		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
 			assert.Equal(t, "newnews", cmd)
-			assert.Equal(t, []string{"*", "20231112", "080000"}, params)
+			assert.Equal(t, []string{"*", "20231112", "130000", "GMT"}, params)
 			writeLines(c, "230 list", "<article1>", "<article2>", ".")
 		}
 
@@ -93,3 +96,95 @@ func Test_NewNews(t *testing.T) {
 		assert.Equal(t, expected, list)
 	})
 }
+
+func Test_QueryNewNews(t *testing.T) {
+	// 2023-11-12T13:00:00.000Z
+	since := time.Unix(1699794000, 0).UTC()
+
+	t.Run("splits long wildmats and combines the results", func(t *testing.T) {
+		var received [][]string
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			received = append(received, params)
+			switch params[0] {
+			case "alt.one.*,!*.test,alt.two.*,!*.test":
+				writeLines(c, "230 list", "<a@example>", "<b@example>", ".")
+			default:
+				writeLines(c, "230 list", "<b@example>", "<c@example>", ".")
+			}
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		query := NewNewsQuery{
+			Wildmats:      []string{"!*.old", "alt.one.*,!*.test", "alt.two.*", "alt.three.*", "!*.test"},
+			Since:         since,
+			Distributions: []string{"world", "local"},
+			MaxLength:     len("NEWNEWS alt.one.*,!*.test,alt.two.*,!*.test 20231112 130000 GMT <world,local>"),
+		}
+		list, err := client.QueryNewNews(query)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"<a@example>", "<b@example>", "<c@example>"}, list)
+
+		expected := [][]string{
+			{"alt.one.*,!*.test,alt.two.*,!*.test", "20231112", "130000", "GMT", "<world,local>"},
+			{"alt.three.*,!*.test", "20231112", "130000", "GMT", "<world,local>"},
+		}
+		assert.Equal(t, expected, received)
+	})
+
+	t.Run("does not query without positive patterns", func(t *testing.T) {
+		client := Client{}
+		list, err := client.QueryNewNews(NewNewsQuery{Wildmats: []string{"!alt.*"}, Since: since})
+		assert.Nil(t, err)
+		assert.Empty(t, list)
+	})
+
+	t.Run("returns error for patterns that cannot be split", func(t *testing.T) {
+		client := Client{}
+		query := NewNewsQuery{Wildmats: []string{strings.Repeat("a", 600)}, Since: since}
+		list, err := client.QueryNewNews(query)
+		assert.Nil(t, list)
+		assert.ErrorContains(t, err, "wildmat is too long for a single command")
+	})
+}
+
+func Test_splitWildmat(t *testing.T) {
+	patterns := []string{"!x.*", "a.*", "!a.b.*", "b.*", "c.*", "!c.d.*", "d.*"}
+
+	tests := []struct {
+		maxLength int
+		expected  []string
+	}{
+		{100, []string{"a.*,!a.b.*,b.*,c.*,!c.d.*,d.*"}},
+		{20, []string{"a.*,!a.b.*,!c.d.*", "b.*,c.*,!c.d.*,d.*"}},
+		{17, []string{"a.*,!a.b.*,!c.d.*", "b.*,c.*,!c.d.*", "d.*"}},
+	}
+	for _, test := range tests {
+		wildmats, err := splitWildmat(patterns, test.maxLength)
+		require.Nil(t, err)
+		assert.Equal(t, test.expected, wildmats, test.maxLength)
+	}
+
+	_, err := splitWildmat(patterns, 16)
+	assert.ErrorContains(t, err, `wildmat is too long for a single command: "a.*,!a.b.*,!c.d.*"`)
+
+	wildmats, err := splitWildmat([]string{"!a.*"}, 10)
+	assert.Nil(t, err)
+	assert.Nil(t, wildmats)
+
+	t.Run("matches the same groups", func(t *testing.T) {
+		complete := wildmat.MustCompile(strings.Join(patterns, ","))
+		wildmats, err := splitWildmat(patterns, 17)
+		require.Nil(t, err)
+
+		groups := []string{"a.x", "a.b.x", "b.x", "c.x", "c.d.x", "d.x", "x.y", "x.a", "e.x"}
+		for _, group := range groups {
+			matched := false
+			for _, w := range wildmats {
+				matched = matched || wildmat.MustCompile(w).Match(group)
+			}
+			assert.Equal(t, complete.Match(group), matched, group)
+		}
+	})
+}
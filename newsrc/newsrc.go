@@ -0,0 +1,245 @@
+// Package newsrc reads and writes `.newsrc` files, the format traditional
+// newsreaders use to record the subscribed groups and the articles that have
+// been read in each group:
+//
+//	options -n all
+//	comp.lang.go: 1-100,105
+//	alt.test! 1-20
+//
+// Subscribed groups are followed by a colon, unsubscribed groups by an
+// exclamation mark. Combined with the article numbers reported by the server,
+// e.g. via [nntpclient.Client.Group], the unread articles of a group can be
+// computed:
+//
+//	summary, err := client.Group("comp.lang.go")
+//	// ...
+//	for _, r := range rc.MergeGroup(summary) {
+//		it := client.IterateGroup(summary.Name, nntpclient.IterateRange(r.Low, r.High))
+//		// ...
+//	}
+package newsrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/popnzb/nntpclient"
+)
+
+// Group is the read state of a single group.
+type Group struct {
+	Name       string
+	Subscribed bool
+	Read       RangeSet
+}
+
+// Unread returns the article numbers from low to high that have not been
+// read. The low and high water marks reported by the server are typically
+// used as bounds.
+func (g *Group) Unread(low int, high int) RangeSet {
+	return g.Read.Complement(max(low, 1), high)
+}
+
+// MarkRead marks the articles from low to high as read.
+func (g *Group) MarkRead(low int, high int) {
+	g.Read = g.Read.Add(low, high)
+}
+
+// MarkUnread marks the articles from low to high as unread.
+func (g *Group) MarkUnread(low int, high int) {
+	g.Read = g.Read.Remove(low, high)
+}
+
+// Newsrc is the content of a `.newsrc` file. The zero value is an empty file
+// that is ready to use.
+type Newsrc struct {
+	// Options holds the `options` line of the file, if any, without the
+	// leading `options` keyword. It is written back unchanged.
+	Options string
+	// Groups are the groups in the order they appear in the file. Use
+	// [Newsrc.Group] and [Newsrc.Add] to find and add groups.
+	Groups []*Group
+
+	index map[string]*Group
+}
+
+// Parse reads a `.newsrc` file. Blank lines are ignored. Groups that are
+// listed more than once are merged.
+func Parse(reader io.Reader) (*Newsrc, error) {
+	rc := &Newsrc{}
+	scanner := bufio.NewScanner(reader)
+	// Read ranges of busy groups can be very long.
+	scanner.Buffer(nil, 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if options, found := strings.CutPrefix(line, "options "); found {
+			rc.Options = strings.TrimSpace(options)
+			continue
+		}
+
+		index := strings.IndexAny(line, ":!")
+		if index <= 0 {
+			return nil, fmt.Errorf("newsrc: line %d: missing subscription marker: %q", lineNumber, line)
+		}
+		read, err := ParseRangeSet(line[index+1:])
+		if err != nil {
+			return nil, fmt.Errorf("newsrc: line %d: %w", lineNumber, err)
+		}
+
+		group := rc.Add(strings.TrimSpace(line[:index]))
+		group.Subscribed = group.Subscribed || line[index] == ':'
+		for _, r := range read {
+			group.MarkRead(r.Low, r.High)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("newsrc: %w", err)
+	}
+	return rc, nil
+}
+
+// Load reads the `.newsrc` file at path. If the file does not exist, an empty
+// [Newsrc] is returned.
+func Load(path string) (*Newsrc, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &Newsrc{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// WriteTo writes the file in the `.newsrc` format.
+func (rc *Newsrc) WriteTo(writer io.Writer) (int64, error) {
+	var builder strings.Builder
+	if rc.Options != "" {
+		fmt.Fprintf(&builder, "options %s\n", rc.Options)
+	}
+	for _, group := range rc.Groups {
+		marker := "!"
+		if group.Subscribed {
+			marker = ":"
+		}
+		builder.WriteString(group.Name + marker)
+		if len(group.Read) > 0 {
+			builder.WriteString(" " + group.Read.String())
+		}
+		builder.WriteByte('\n')
+	}
+
+	written, err := io.WriteString(writer, builder.String())
+	return int64(written), err
+}
+
+// Save writes the file to path. The file is replaced atomically, so that it
+// is not corrupted if writing fails.
+func (rc *Newsrc) Save(path string) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = rc.WriteTo(temp)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Group returns the named group, or nil if it is not listed.
+func (rc *Newsrc) Group(name string) *Group {
+	if rc.index == nil || len(rc.index) != len(rc.Groups) {
+		rc.reindex()
+	}
+	return rc.index[name]
+}
+
+// Add returns the named group, adding it as an unsubscribed group without
+// read articles if it is not listed yet.
+func (rc *Newsrc) Add(name string) *Group {
+	if group := rc.Group(name); group != nil {
+		return group
+	}
+	group := &Group{Name: name}
+	rc.Groups = append(rc.Groups, group)
+	rc.index[name] = group
+	return group
+}
+
+// Subscribe marks the named group as subscribed, adding it if necessary.
+func (rc *Newsrc) Subscribe(name string) *Group {
+	group := rc.Add(name)
+	group.Subscribed = true
+	return group
+}
+
+// Unsubscribe marks the named group as unsubscribed. The read articles of
+// the group are retained.
+func (rc *Newsrc) Unsubscribe(name string) {
+	if group := rc.Group(name); group != nil {
+		group.Subscribed = false
+	}
+}
+
+// reindex rebuilds the index of groups by name. The index is rebuilt lazily
+// whenever groups have been added to, or removed from, the Groups field
+// directly.
+func (rc *Newsrc) reindex() {
+	rc.index = make(map[string]*Group, len(rc.Groups))
+	for _, group := range rc.Groups {
+		rc.index[group.Name] = group
+	}
+}
+
+// MergeGroup updates the read state of a group with the article numbers
+// reported by [nntpclient.Client.Group], and returns the unread articles of
+// the group. Articles below the low water mark no longer exist on the server
+// and are marked as read, which keeps the ranges short. The group is added,
+// unsubscribed, if it is not listed yet.
+func (rc *Newsrc) MergeGroup(summary *nntpclient.GroupSummary) RangeSet {
+	if summary.Number == 0 {
+		// The water marks of empty groups are unreliable, see RFC 3977 §6.1.1.2.
+		return rc.merge(summary.Name, summary.Low, summary.Low-1)
+	}
+	return rc.merge(summary.Name, summary.Low, summary.High)
+}
+
+// MergeActive updates the read state of the subscribed groups with the article
+// numbers reported by [nntpclient.Client.ListActive], and returns the unread
+// articles of each subscribed group that is included in active.
+func (rc *Newsrc) MergeActive(active map[string]nntpclient.ListGroup) map[string]RangeSet {
+	result := make(map[string]RangeSet)
+	for _, group := range rc.Groups {
+		listed, found := active[group.Name]
+		if !group.Subscribed || !found {
+			continue
+		}
+		result[group.Name] = rc.merge(group.Name, listed.Low, listed.High)
+	}
+	return result
+}
+
+func (rc *Newsrc) merge(name string, low int, high int) RangeSet {
+	group := rc.Add(name)
+	if low > 1 {
+		group.MarkRead(1, low-1)
+	}
+	return group.Unread(low, high)
+}
@@ -0,0 +1,140 @@
+package newsrc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/popnzb/nntpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `options -n all !alt.binaries.*
+
+comp.lang.go: 1-100,105
+alt.test! 1-20
+news.announce.newusers:
+comp.lang.go: 101-104
+`
+
+func Test_Parse(t *testing.T) {
+	t.Run("parses files", func(t *testing.T) {
+		rc, err := Parse(strings.NewReader(sample))
+		require.Nil(t, err)
+
+		assert.Equal(t, "-n all !alt.binaries.*", rc.Options)
+		expected := []*Group{
+			{Name: "comp.lang.go", Subscribed: true, Read: RangeSet{{1, 105}}},
+			{Name: "alt.test", Subscribed: false, Read: RangeSet{{1, 20}}},
+			{Name: "news.announce.newusers", Subscribed: true},
+		}
+		assert.Equal(t, expected, rc.Groups)
+		assert.Equal(t, expected[1], rc.Group("alt.test"))
+		assert.Nil(t, rc.Group("missing"))
+	})
+
+	t.Run("returns errors", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("comp.lang.go: 1-100\nbroken line\n"))
+		assert.EqualError(t, err, `newsrc: line 2: missing subscription marker: "broken line"`)
+
+		_, err = Parse(strings.NewReader("comp.lang.go: 1-x\n"))
+		assert.EqualError(t, err, `newsrc: line 1: invalid range "1-x"`)
+	})
+}
+
+func Test_WriteTo(t *testing.T) {
+	rc, err := Parse(strings.NewReader(sample))
+	require.Nil(t, err)
+
+	var out bytes.Buffer
+	written, err := rc.WriteTo(&out)
+	require.Nil(t, err)
+
+	expected := "options -n all !alt.binaries.*\n" +
+		"comp.lang.go: 1-105\n" +
+		"alt.test! 1-20\n" +
+		"news.announce.newusers:\n"
+	assert.Equal(t, expected, out.String())
+	assert.Equal(t, int64(len(expected)), written)
+}
+
+func Test_LoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".newsrc")
+
+	rc, err := Load(path)
+	require.Nil(t, err)
+	assert.Len(t, rc.Groups, 0)
+
+	rc.Subscribe("alt.test").MarkRead(1, 10)
+	require.Nil(t, rc.Save(path))
+
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "alt.test: 1-10\n", string(content))
+
+	loaded, err := Load(path)
+	require.Nil(t, err)
+	assert.Equal(t, rc.Groups, loaded.Groups)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func Test_Newsrc(t *testing.T) {
+	t.Run("manages subscriptions", func(t *testing.T) {
+		rc := &Newsrc{}
+		group := rc.Subscribe("alt.test")
+		assert.Equal(t, true, group.Subscribed)
+		assert.Equal(t, group, rc.Add("alt.test"))
+
+		group.MarkRead(1, 10)
+		rc.Unsubscribe("alt.test")
+		rc.Unsubscribe("missing")
+		assert.Equal(t, []*Group{{Name: "alt.test", Read: RangeSet{{1, 10}}}}, rc.Groups)
+
+		group.MarkUnread(5, 5)
+		assert.Equal(t, RangeSet{{1, 4}, {6, 10}}, group.Read)
+	})
+
+	t.Run("indexes groups added directly", func(t *testing.T) {
+		rc := &Newsrc{}
+		rc.Add("one")
+		rc.Groups = append(rc.Groups, &Group{Name: "two"})
+		assert.NotNil(t, rc.Group("two"))
+	})
+
+	t.Run("merges group summaries", func(t *testing.T) {
+		rc, err := Parse(strings.NewReader("alt.test: 1-20,25\n"))
+		require.Nil(t, err)
+
+		unread := rc.MergeGroup(&nntpclient.GroupSummary{Name: "alt.test", Number: 20, Low: 10, High: 30})
+		assert.Equal(t, RangeSet{{21, 24}, {26, 30}}, unread)
+
+		// Expired articles are marked as read.
+		unread = rc.MergeGroup(&nntpclient.GroupSummary{Name: "alt.test", Number: 3, Low: 28, High: 30})
+		assert.Equal(t, RangeSet{{28, 30}}, unread)
+		assert.Equal(t, RangeSet{{1, 27}}, rc.Group("alt.test").Read)
+
+		unread = rc.MergeGroup(&nntpclient.GroupSummary{Name: "alt.empty", Number: 0, Low: 5, High: 50})
+		assert.Nil(t, unread)
+		assert.Equal(t, &Group{Name: "alt.empty", Read: RangeSet{{1, 4}}}, rc.Group("alt.empty"))
+	})
+
+	t.Run("merges active lists", func(t *testing.T) {
+		rc, err := Parse(strings.NewReader("alt.one: 1-5\nalt.two! 1-5\nalt.three:\n"))
+		require.Nil(t, err)
+
+		active := map[string]nntpclient.ListGroup{
+			"alt.one":   {Name: "alt.one", Low: 1, High: 10, Status: "y"},
+			"alt.two":   {Name: "alt.two", Low: 1, High: 10, Status: "y"},
+			"alt.other": {Name: "alt.other", Low: 1, High: 10, Status: "y"},
+		}
+		unread := rc.MergeActive(active)
+		assert.Equal(t, map[string]RangeSet{"alt.one": {{6, 10}}}, unread)
+		assert.Len(t, rc.Groups, 3)
+	})
+}
@@ -0,0 +1,161 @@
+package newsrc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive range of article numbers.
+type Range struct {
+	Low  int
+	High int
+}
+
+// RangeSet is a set of article numbers represented as sorted, non-overlapping,
+// and non-adjacent ranges. The zero value is an empty set. RangeSet values
+// are not modified by its methods, which instead return new sets.
+type RangeSet []Range
+
+// ParseRangeSet parses a list of ranges as found in `.newsrc` files, e.g.
+// `1-100,105,110-120`. Ranges may be given in any order and may overlap.
+// Ranges whose upper bound is lower than their lower bound, such as `1-0`,
+// are ignored.
+func ParseRangeSet(value string) (RangeSet, error) {
+	var result RangeSet
+	for _, element := range strings.Split(value, ",") {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+
+		lowText, highText, isRange := strings.Cut(element, "-")
+		low, err := strconv.Atoi(strings.TrimSpace(lowText))
+		if err != nil || low < 0 {
+			return nil, fmt.Errorf("invalid range %q", element)
+		}
+		high := low
+		if isRange {
+			high, err = strconv.Atoi(strings.TrimSpace(highText))
+			if err != nil || high < 0 {
+				return nil, fmt.Errorf("invalid range %q", element)
+			}
+		}
+		result = result.Add(low, high)
+	}
+	return result, nil
+}
+
+// String formats the set as in `.newsrc` files, e.g. `1-100,105`.
+func (s RangeSet) String() string {
+	var builder strings.Builder
+	for i, r := range s {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(strconv.Itoa(r.Low))
+		if r.High != r.Low {
+			builder.WriteByte('-')
+			builder.WriteString(strconv.Itoa(r.High))
+		}
+	}
+	return builder.String()
+}
+
+// Contains indicates if the article number is in the set.
+func (s RangeSet) Contains(number int) bool {
+	for _, r := range s {
+		if number < r.Low {
+			return false
+		}
+		if number <= r.High {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of article numbers in the set.
+func (s RangeSet) Count() int {
+	count := 0
+	for _, r := range s {
+		count += r.High - r.Low + 1
+	}
+	return count
+}
+
+// Add returns a set that additionally contains the numbers from low to high.
+// If high is lower than low, the set is returned unchanged.
+func (s RangeSet) Add(low int, high int) RangeSet {
+	if high < low {
+		return s
+	}
+
+	result := make(RangeSet, 0, len(s)+1)
+	added := Range{Low: low, High: high}
+	inserted := false
+	for _, r := range s {
+		switch {
+		case r.High+1 < added.Low:
+			result = append(result, r)
+		case added.High+1 < r.Low:
+			if !inserted {
+				result = append(result, added)
+				inserted = true
+			}
+			result = append(result, r)
+		default:
+			// The ranges overlap or are adjacent.
+			added.Low = min(added.Low, r.Low)
+			added.High = max(added.High, r.High)
+		}
+	}
+	if !inserted {
+		result = append(result, added)
+	}
+	return result
+}
+
+// Remove returns a set without the numbers from low to high.
+func (s RangeSet) Remove(low int, high int) RangeSet {
+	if high < low {
+		return s
+	}
+
+	result := make(RangeSet, 0, len(s)+1)
+	for _, r := range s {
+		if r.High < low || r.Low > high {
+			result = append(result, r)
+			continue
+		}
+		if r.Low < low {
+			result = append(result, Range{Low: r.Low, High: low - 1})
+		}
+		if r.High > high {
+			result = append(result, Range{Low: high + 1, High: r.High})
+		}
+	}
+	return result
+}
+
+// Complement returns the numbers from low to high that are not in the set.
+func (s RangeSet) Complement(low int, high int) RangeSet {
+	var result RangeSet
+	next := low
+	for _, r := range s {
+		if r.High < next {
+			continue
+		}
+		if r.Low > high {
+			break
+		}
+		if r.Low > next {
+			result = append(result, Range{Low: next, High: r.Low - 1})
+		}
+		next = r.High + 1
+	}
+	if next <= high {
+		result = append(result, Range{Low: next, High: high})
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package newsrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRangeSet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RangeSet
+	}{
+		{"", nil},
+		{"1-100,105", RangeSet{{1, 100}, {105, 105}}},
+		{" 110-120 , 1-5,3-8,9", RangeSet{{1, 9}, {110, 120}}},
+		{"1-0,5", RangeSet{{5, 5}}},
+		{"0", RangeSet{{0, 0}}},
+	}
+	for _, test := range tests {
+		actual, err := ParseRangeSet(test.input)
+		require.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, actual, test.input)
+	}
+
+	for _, input := range []string{"a", "1-b", "-5", "1--5"} {
+		_, err := ParseRangeSet(input)
+		assert.ErrorContains(t, err, "invalid range", input)
+	}
+}
+
+func Test_RangeSet(t *testing.T) {
+	set := RangeSet{{1, 100}, {105, 105}, {110, 120}}
+
+	t.Run("formats", func(t *testing.T) {
+		assert.Equal(t, "1-100,105,110-120", set.String())
+		assert.Equal(t, "", RangeSet{}.String())
+	})
+
+	t.Run("checks membership", func(t *testing.T) {
+		assert.Equal(t, true, set.Contains(1))
+		assert.Equal(t, true, set.Contains(105))
+		assert.Equal(t, false, set.Contains(104))
+		assert.Equal(t, false, set.Contains(121))
+		assert.Equal(t, 112, set.Count())
+	})
+
+	t.Run("adds ranges", func(t *testing.T) {
+		assert.Equal(t, RangeSet{{1, 100}, {102, 102}, {105, 105}, {110, 120}}, set.Add(102, 102))
+		assert.Equal(t, RangeSet{{1, 105}, {110, 120}}, set.Add(101, 104))
+		assert.Equal(t, RangeSet{{1, 130}}, set.Add(50, 130))
+		assert.Equal(t, RangeSet{{1, 100}, {105, 105}, {110, 121}}, set.Add(121, 121))
+		assert.Equal(t, set, set.Add(5, 4))
+		assert.Equal(t, RangeSet{{3, 4}}, RangeSet(nil).Add(3, 4))
+		// The original is unchanged.
+		assert.Equal(t, RangeSet{{1, 100}, {105, 105}, {110, 120}}, set)
+	})
+
+	t.Run("removes ranges", func(t *testing.T) {
+		assert.Equal(t, RangeSet{{1, 49}, {61, 100}, {105, 105}, {110, 120}}, set.Remove(50, 60))
+		assert.Equal(t, RangeSet{{1, 99}, {111, 120}}, set.Remove(100, 110))
+		assert.Equal(t, RangeSet{}, set.Remove(0, 200))
+		assert.Equal(t, set, set.Remove(5, 4))
+	})
+
+	t.Run("computes complements", func(t *testing.T) {
+		assert.Equal(t, RangeSet{{101, 104}, {106, 109}, {121, 130}}, set.Complement(50, 130))
+		assert.Equal(t, RangeSet{{102, 103}}, set.Complement(102, 103))
+		assert.Nil(t, set.Complement(1, 100))
+		assert.Equal(t, RangeSet{{1, 10}}, RangeSet(nil).Complement(1, 10))
+		assert.Nil(t, set.Complement(10, 5))
+	})
+}
@@ -1,24 +1,38 @@
 package nntpclient
 
-// Next selects the next article in the selected group.
-func (c *Client) Next() error {
+// Next selects the next article in the selected group. The article number
+// and message id of the newly selected article are returned.
+func (c *Client) Next() (int, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("READER"); err != nil {
+		return -1, "", err
+	}
+
 	code, message, err := c.sendCommand("NEXT")
 	if err != nil {
-		return err
+		return -1, "", err
 	}
 
 	switch code {
 	case 412:
-		return ErrNoGroupSelected
+		return -1, "", ErrNoGroupSelected
 	case 420:
-		return ErrCurrentArticleNumInvalid
+		return -1, "", ErrCurrentArticleNumInvalid
 	case 421:
-		return ErrNoNextArticle
+		return -1, "", ErrNoNextArticle
 	}
 
 	if code != 223 {
-		return UnexpectedError(code, message)
+		return -1, "", UnexpectedError(code, message)
+	}
+
+	number, messageId, err := parseArticleResponse(message)
+	if err != nil {
+		return -1, "", err
 	}
+	c.trackArticle("", message)
 
-	return nil
+	return number, messageId, nil
 }
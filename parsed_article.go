@@ -0,0 +1,241 @@
+package nntpclient
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Article is an article retrieved with [Client.FetchArticle], or parsed with
+// [ParseArticle], with the commonly used headers parsed into typed fields.
+//
+// Parsing is lenient: a header that is missing or cannot be parsed leaves its
+// field at the zero value. The original headers are always available via
+// Headers.
+type Article struct {
+	MessageId string
+	// Subject is decoded according to RFC 2047. Encoded words in UTF-8,
+	// ISO-8859-1, and US-ASCII are always decoded. Words in other charsets
+	// are only decoded if a [CharsetReader] is provided, see
+	// [ArticleParser] and [WithCharsetReader], and are retained as is
+	// otherwise.
+	Subject string
+	// From is nil if the header is missing or not a valid address.
+	From       *mail.Address
+	Date       time.Time
+	Newsgroups []string
+	// References lists the message ids of the ancestors of the article,
+	// oldest first.
+	References []string
+	Xref       Xref
+	// Path lists the servers the article has passed through, most recent
+	// first.
+	Path  []string
+	Lines int
+	Bytes int
+
+	Headers textproto.MIMEHeader
+	// RawHeaders retains the original order and case of the headers. It is
+	// only set by [Client.FetchArticle].
+	RawHeaders HeaderBlock
+	// Body is the body of the article. [Client.FetchArticle] removes the
+	// dot-stuffing applied in transit, see RFC 3977 §3.1.1.
+	Body []byte
+}
+
+// Xref is the parsed value of the `Xref` header, which lists the article
+// numbers assigned to the article on the named server.
+type Xref struct {
+	Server  string
+	Entries []XrefEntry
+}
+
+// XrefEntry is the article number of an article in a single group.
+type XrefEntry struct {
+	Group  string
+	Number int
+}
+
+// CharsetReader converts input in the named charset to UTF-8. It is used to
+// decode the RFC 2047 encoded words of headers in charsets other than UTF-8,
+// ISO-8859-1, and US-ASCII. The signature matches the one of
+// [mime.WordDecoder], so that, for example, the readers provided by
+// `golang.org/x/net/html/charset` can be used.
+type CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+// ArticleParser creates [Article] values from the headers and bodies of
+// articles. The zero value is ready to use.
+type ArticleParser struct {
+	// CharsetReader, if not nil, is consulted for the encoded words of the
+	// headers in charsets that are not built in, see [CharsetReader].
+	CharsetReader CharsetReader
+}
+
+// WithCharsetReader makes [Client.FetchArticle] decode the headers of
+// articles using the given [CharsetReader], see [ArticleParser].
+func WithCharsetReader(reader CharsetReader) Option {
+	return func(client *Client) {
+		client.charsetReader = reader
+	}
+}
+
+// dateLayouts are obsolete date formats found in Usenet articles that are not
+// accepted by [mail.ParseDate], e.g. those of RFC 850 and asctime.
+var dateLayouts = []string{
+	"Monday, 02-Jan-06 15:04:05 MST",
+	"Mon, 02-Jan-06 15:04:05 MST",
+	"02-Jan-06 15:04:05 MST",
+	time.UnixDate,
+	time.ANSIC,
+}
+
+// FetchArticle retrieves an article with `ARTICLE` and parses its headers. The
+// id parameter is handled in the same way as it is in [Client.Article]. Unlike
+// [Client.Article], the body is returned with dot-stuffing removed.
+func (c *Client) FetchArticle(id string) (*Article, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var body bytes.Buffer
+	headers, err := c.article(id, &body)
+	if err != nil {
+		return nil, err
+	}
+	parser := ArticleParser{CharsetReader: c.charsetReader}
+	article := parser.Parse(headers.MIMEHeader(), unstuffBody(body.Bytes()))
+	article.RawHeaders = headers
+	return article, nil
+}
+
+// unstuffBody removes the leading dot that is added in transit to every line
+// of a body that starts with a dot.
+func unstuffBody(body []byte) []byte {
+	if !bytes.HasPrefix(body, []byte("..")) && !bytes.Contains(body, []byte("\n..")) {
+		return body
+	}
+
+	result := make([]byte, 0, len(body))
+	for len(body) > 0 {
+		line := body
+		if end := bytes.IndexByte(body, '\n'); end >= 0 {
+			line = body[:end+1]
+		}
+		body = body[len(line):]
+		if bytes.HasPrefix(line, []byte("..")) {
+			line = line[1:]
+		}
+		result = append(result, line...)
+	}
+	return result
+}
+
+// ParseArticle creates an [Article] from the headers and body of an article
+// retrieved by other means, e.g. [Client.Article], with a zero
+// [ArticleParser].
+func ParseArticle(headers textproto.MIMEHeader, body []byte) *Article {
+	return (&ArticleParser{}).Parse(headers, body)
+}
+
+// Parse creates an [Article] from the headers and body of an article. The
+// body is used as is, i.e. it must not be dot-stuffed.
+func (p *ArticleParser) Parse(headers textproto.MIMEHeader, body []byte) *Article {
+	article := &Article{
+		MessageId:  strings.TrimSpace(headers.Get("Message-Id")),
+		Subject:    decodeHeader(headers.Get("Subject"), p.CharsetReader),
+		Date:       parseDate(headers.Get("Date")),
+		Newsgroups: splitList(headers.Get("Newsgroups"), ","),
+		References: parseMessageIds(headers.Get("References")),
+		Xref:       parseXref(headers.Get("Xref")),
+		Path:       splitList(headers.Get("Path"), "!"),
+		Headers:    headers,
+		Body:       body,
+	}
+
+	if from := headers.Get("From"); from != "" {
+		parser := mail.AddressParser{WordDecoder: &mime.WordDecoder{CharsetReader: p.CharsetReader}}
+		if address, err := parser.Parse(from); err == nil {
+			article.From = address
+		}
+	}
+	article.Lines, _ = strconv.Atoi(strings.TrimSpace(headers.Get("Lines")))
+	article.Bytes, _ = strconv.Atoi(strings.TrimSpace(headers.Get("Bytes")))
+
+	return article
+}
+
+// parseDate parses the value of a `Date` header in any of the RFC 5322 formats,
+// including the obsolete ones, or in the formats of older Usenet software.
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if date, err := mail.ParseDate(value); err == nil {
+		return date
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+// splitList splits value by separator and discards empty elements.
+func splitList(value string, separator string) []string {
+	var result []string
+	for _, element := range strings.Split(value, separator) {
+		element = strings.TrimSpace(element)
+		if element != "" {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+// parseMessageIds extracts the message ids, including brackets, from a list
+// such as the value of a `References` header. Anything outside the brackets is
+// ignored.
+func parseMessageIds(value string) []string {
+	var result []string
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			return result
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			return result
+		}
+		result = append(result, value[start:start+end+1])
+		value = value[start+end+1:]
+	}
+}
+
+// parseXref parses the value of an `Xref` header, e.g.
+// `news.example.com alt.test:1 alt.misc:20`. Malformed entries are skipped.
+func parseXref(value string) Xref {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return Xref{}
+	}
+
+	xref := Xref{Server: fields[0]}
+	for _, field := range fields[1:] {
+		group, number, found := strings.Cut(field, ":")
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			continue
+		}
+		xref.Entries = append(xref.Entries, XrefEntry{Group: group, Number: n})
+	}
+	return xref
+}
@@ -0,0 +1,255 @@
+package nntpclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/popnzb/nntpclient/mimewalk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FetchArticle(t *testing.T) {
+	t.Run("parses a real article", func(t *testing.T) {
+		sample, err := os.ReadFile("testdata/sample_article.txt")
+		require.Nil(t, err)
+
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			io.WriteString(c, strings.ReplaceAll(string(sample), "\n", "\r\n"))
+		}
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		article, err := client.FetchArticle("<1p81j8s.l0kmdc1b6stnjN%!@!.invalid>")
+		require.Nil(t, err)
+
+		assert.Equal(t, "<1p81j8s.l0kmdc1b6stnjN%!@!.invalid>", article.MessageId)
+		assert.Equal(t, "Gordon", article.Subject)
+		assert.Equal(t, &mail.Address{Name: "Ï", Address: "!@!.invalid"}, article.From)
+		assert.Equal(t, true, time.Date(2021, 4, 22, 13, 8, 35, 0, time.UTC).Equal(article.Date))
+		assert.Equal(t, []string{"free.rocks"}, article.Newsgroups)
+		assert.Nil(t, article.References)
+		assert.Equal(t, Xref{Server: "news.netfront.net", Entries: []XrefEntry{{"free.rocks", 1}}}, article.Xref)
+		assert.Len(t, article.Path, 10)
+		assert.Equal(t, "news.netfront.net", article.Path[0])
+		assert.Equal(t, "not-for-mail", article.Path[9])
+		assert.Equal(t, 4, article.Lines)
+		assert.Equal(t, 0, article.Bytes)
+		assert.Equal(t, "Rock on, Gordon.\r\n\r\n--\r\nfold, spindle, mutilate.\r\n", string(article.Body))
+		assert.Equal(t, "MacSOUP/2.8.6b1 (ed136d9b90) (Mac OS 10.14.6)", article.Headers.Get("User-Agent"))
+		require.Len(t, article.RawHeaders, 13)
+		assert.Equal(t, "Path", article.RawHeaders[0].Name)
+		assert.Equal(t, "Message-ID", article.RawHeaders[7].Name)
+		assert.Equal(t, "Xref", article.RawHeaders[12].Name)
+	})
+
+	t.Run("removes dot-stuffing", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "220 1 <a@example>", "Subject: dots", "", "line one", "..hidden", "...", ".")
+		}
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		article, err := client.FetchArticle("<a@example>")
+		require.Nil(t, err)
+		assert.Equal(t, "line one\r\n.hidden\r\n..\r\n", string(article.Body))
+	})
+
+	t.Run("yields unstuffed parts to mimewalk", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(
+				c,
+				"220 1 <a@example>",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Transfer-Encoding: quoted-printable",
+				"",
+				"line one",
+				"..hidden caf=C3=A9",
+				".",
+			)
+		}
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		article, err := client.FetchArticle("<a@example>")
+		require.Nil(t, err)
+		parts, err := mimewalk.Parts(article.Headers, article.Body)
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+		assert.Equal(t, "line one\r\n.hidden café\r\n", string(parts[0].Content))
+	})
+
+	t.Run("returns errors", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "430 no such article")
+		}
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		article, err := client.FetchArticle("<foo@example>")
+		assert.Nil(t, article)
+		assert.Equal(t, true, errors.Is(err, ErrNoArticleWithId))
+	})
+}
+
+func Test_ParseArticle(t *testing.T) {
+	t.Run("parses headers", func(t *testing.T) {
+		headers := textproto.MIMEHeader{
+			"Message-Id": {" <c@example> "},
+			"Subject":    {"Re: =?UTF-8?B?w6lsw6h2ZQ==?= =?ISO-8859-1?Q?=E9t=E9?="},
+			"From":       {"user@example.com (A User)"},
+			"Date":       {"Monday, 02-Jan-06 15:04:05 GMT"},
+			"Newsgroups": {"alt.test, alt.misc,"},
+			"References": {"<a@example> <b@example>,\t<broken"},
+			"Xref":       {"news.example.com alt.test:10 broken alt.misc:x alt.misc:20"},
+			"Path":       {"one!two!not-for-mail"},
+			"Lines":      {"12"},
+			"Bytes":      {" 1024"},
+		}
+
+		article := ParseArticle(headers, []byte("body"))
+		expected := &Article{
+			MessageId:  "<c@example>",
+			Subject:    "Re: élèveété",
+			From:       &mail.Address{Name: "A User", Address: "user@example.com"},
+			Date:       time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			Newsgroups: []string{"alt.test", "alt.misc"},
+			References: []string{"<a@example>", "<b@example>"},
+			Xref: Xref{
+				Server:  "news.example.com",
+				Entries: []XrefEntry{{"alt.test", 10}, {"alt.misc", 20}},
+			},
+			Path:    []string{"one", "two", "not-for-mail"},
+			Lines:   12,
+			Bytes:   1024,
+			Headers: headers,
+			Body:    []byte("body"),
+		}
+		assert.Equal(t, expected.Date.Unix(), article.Date.Unix())
+		article.Date = expected.Date
+		assert.Equal(t, expected, article)
+	})
+
+	t.Run("leaves invalid headers empty", func(t *testing.T) {
+		headers := textproto.MIMEHeader{
+			"Subject": {"=?x-unknown?Q?foo?="},
+			"From":    {"not an address"},
+			"Date":    {"yesterday"},
+			"Lines":   {"many"},
+		}
+
+		article := ParseArticle(headers, nil)
+		assert.Equal(t, "=?x-unknown?Q?foo?=", article.Subject)
+		assert.Nil(t, article.From)
+		assert.Equal(t, true, article.Date.IsZero())
+		assert.Equal(t, 0, article.Lines)
+		assert.Equal(t, Xref{}, article.Xref)
+	})
+}
+
+// latin9Reader is a [CharsetReader] for ISO-8859-15. Of the characters that
+// differ from ISO-8859-1, only the euro sign is converted.
+func latin9Reader(charset string, input io.Reader) (io.Reader, error) {
+	if charset != "iso-8859-15" {
+		return nil, errors.New("unsupported charset " + charset)
+	}
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	var result strings.Builder
+	for _, b := range content {
+		if b == 0xa4 {
+			result.WriteRune('€')
+			continue
+		}
+		result.WriteRune(rune(b))
+	}
+	return strings.NewReader(result.String()), nil
+}
+
+func Test_ArticleParser(t *testing.T) {
+	headers := textproto.MIMEHeader{
+		"Subject": {"=?iso-8859-15?Q?10_=A4_caf=E9?="},
+		"From":    {"=?iso-8859-15?Q?=A4_Seller?= <seller@example.com>"},
+	}
+
+	t.Run("leaves unsupported charsets encoded", func(t *testing.T) {
+		article := ParseArticle(headers, nil)
+		assert.Equal(t, "=?iso-8859-15?Q?10_=A4_caf=E9?=", article.Subject)
+		assert.Nil(t, article.From)
+	})
+
+	t.Run("uses the charset reader", func(t *testing.T) {
+		parser := ArticleParser{CharsetReader: latin9Reader}
+		article := parser.Parse(headers, nil)
+		assert.Equal(t, "10 € café", article.Subject)
+		assert.Equal(t, &mail.Address{Name: "€ Seller", Address: "seller@example.com"}, article.From)
+	})
+
+	t.Run("is configured for FetchArticle", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "220 1 <a@example>", "Subject: "+headers.Get("Subject"), "", "body", ".")
+		}
+		server, err := NewTestServer(t, handler)
+		require.Nil(t, err)
+		defer server.Close()
+
+		client, err := NewWithPort(server.Host, server.Port, WithLogger(NilLogger), WithCharsetReader(latin9Reader))
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		article, err := client.FetchArticle("<a@example>")
+		require.Nil(t, err)
+		assert.Equal(t, "10 € café", article.Subject)
+	})
+}
+
+func Test_unstuffBody(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"a\r\nb\r\n", "a\r\nb\r\n"},
+		{"..a\r\n", ".a\r\n"},
+		{"a\r\n..\r\n...b\r\n", "a\r\n.\r\n..b\r\n"},
+		{"a..b\r\n..", "a..b\r\n."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, string(unstuffBody([]byte(test.input))))
+		})
+	}
+}
+
+func Test_parseDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"Thu, 22 Apr 2021 14:08:35 +0100", time.Date(2021, 4, 22, 13, 8, 35, 0, time.UTC)},
+		{"22 Apr 21 14:08:35 GMT", time.Date(2021, 4, 22, 14, 8, 35, 0, time.UTC)},
+		{"Thu, 22 Apr 2021 14:08 -0500", time.Date(2021, 4, 22, 19, 8, 0, 0, time.UTC)},
+		{"Thursday, 22-Apr-21 14:08:35 GMT", time.Date(2021, 4, 22, 14, 8, 35, 0, time.UTC)},
+		{"22-Apr-99 14:08:35 GMT", time.Date(1999, 4, 22, 14, 8, 35, 0, time.UTC)},
+		{"Thu Apr 22 14:08:35 2021", time.Date(2021, 4, 22, 14, 8, 35, 0, time.UTC)},
+		{"Thu Apr 22 14:08:35 UTC 2021", time.Date(2021, 4, 22, 14, 8, 35, 0, time.UTC)},
+		{"", time.Time{}},
+		{"not a date", time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected.Unix(), parseDate(test.input).Unix())
+		})
+	}
+}
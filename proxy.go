@@ -0,0 +1,282 @@
+package nntpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ProxyAuth holds the credentials used to authenticate with a proxy server.
+type ProxyAuth struct {
+	Username string
+	Password string
+}
+
+// Socks5Dialer is a [ContextDialer] that establishes connections through
+// a SOCKS5 proxy (RFC 1928). Username and password authentication (RFC 1929)
+// is used when Auth is provided. Otherwise, no authentication is offered to
+// the proxy.
+type Socks5Dialer struct {
+	// ProxyAddress is the `host:port` address of the proxy server.
+	ProxyAddress string
+	// Auth, when not nil, holds the credentials for the proxy server.
+	Auth *ProxyAuth
+	// Forward is the dialer used to connect to the proxy server. When nil,
+	// a zero value [net.Dialer] is used.
+	Forward ContextDialer
+}
+
+// HttpConnectDialer is a [ContextDialer] that establishes connections
+// through an HTTP proxy by issuing a `CONNECT` request. Basic authentication
+// is used when Auth is provided.
+type HttpConnectDialer struct {
+	// ProxyAddress is the `host:port` address of the proxy server.
+	ProxyAddress string
+	// Auth, when not nil, holds the credentials for the proxy server.
+	Auth *ProxyAuth
+	// Forward is the dialer used to connect to the proxy server. When nil,
+	// a zero value [net.Dialer] is used.
+	Forward ContextDialer
+}
+
+// WithSocks5Proxy configures the client to connect through the SOCKS5 proxy
+// at the given address. The auth parameter may be nil if the proxy does not
+// require authentication. See [Socks5Dialer].
+func WithSocks5Proxy(address string, auth *ProxyAuth) Option {
+	return WithContextDialer(&Socks5Dialer{ProxyAddress: address, Auth: auth})
+}
+
+// WithHttpConnectProxy configures the client to connect through the HTTP
+// proxy at the given address. The auth parameter may be nil if the proxy does
+// not require authentication. See [HttpConnectDialer].
+func WithHttpConnectProxy(address string, auth *ProxyAuth) Option {
+	return WithContextDialer(&HttpConnectDialer{ProxyAddress: address, Auth: auth})
+}
+
+// socks5Replies maps the reply codes defined in RFC 1928 §6 to descriptions.
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// DialContext connects to address through the SOCKS5 proxy.
+func (d *Socks5Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := dialProxy(ctx, d.Forward, network, d.ProxyAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	err = withContextDeadline(ctx, conn, func() error {
+		return d.handshake(conn, address)
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (d *Socks5Dialer) handshake(conn net.Conn, address string) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q: %w", portString, err)
+	}
+
+	method := byte(0x00)
+	if d.Auth != nil {
+		method = 0x02
+	}
+	if _, err := conn.Write([]byte{0x05, 0x01, method}); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 {
+		return fmt.Errorf("socks5: unexpected protocol version %d: %w", reply[0], ErrProxy)
+	}
+	if reply[1] != method {
+		return fmt.Errorf("socks5: no acceptable authentication method: %w", ErrProxy)
+	}
+
+	if d.Auth != nil {
+		if len(d.Auth.Username) > 255 || len(d.Auth.Password) > 255 {
+			return fmt.Errorf("socks5: username or password too long: %w", ErrProxy)
+		}
+		request := []byte{0x01, byte(len(d.Auth.Username))}
+		request = append(request, d.Auth.Username...)
+		request = append(request, byte(len(d.Auth.Password)))
+		request = append(request, d.Auth.Password...)
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return fmt.Errorf("socks5: authentication failed: %w", ErrProxy)
+		}
+	}
+
+	request := []byte{0x05, 0x01, 0x00}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return fmt.Errorf("socks5: host name too long: %w", ErrProxy)
+		}
+		request = append(request, 0x03, byte(len(host)))
+		request = append(request, host...)
+	case ip.To4() != nil:
+		request = append(request, 0x01)
+		request = append(request, ip.To4()...)
+	default:
+		request = append(request, 0x04)
+		request = append(request, ip.To16()...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0x00 {
+		description, found := socks5Replies[header[1]]
+		if !found {
+			description = fmt.Sprintf("unknown reply code %d", header[1])
+		}
+		return fmt.Errorf("socks5: %s: %w", description, ErrProxy)
+	}
+
+	// The bound address is of no use to us, but it must be consumed.
+	var remaining int
+	switch header[3] {
+	case 0x01:
+		remaining = net.IPv4len + 2
+	case 0x04:
+		remaining = net.IPv6len + 2
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		remaining = int(length[0]) + 2
+	default:
+		return fmt.Errorf("socks5: unknown bound address type %d: %w", header[3], ErrProxy)
+	}
+	_, err = io.ReadFull(conn, make([]byte, remaining))
+
+	return err
+}
+
+// DialContext connects to address through the HTTP proxy.
+func (d *HttpConnectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := dialProxy(ctx, d.Forward, network, d.ProxyAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader *bufio.Reader
+	err = withContextDeadline(ctx, conn, func() error {
+		var err error
+		reader, err = d.handshake(conn, address)
+		return err
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+func (d *HttpConnectDialer) handshake(conn net.Conn, address string) (*bufio.Reader, error) {
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.Auth != nil {
+		credentials := d.Auth.Username + ":" + d.Auth.Password
+		request.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if err := request.Write(conn); err != nil {
+		return nil, err
+	}
+
+	// The server may start sending immediately after the proxy response, e.g.
+	// the NNTP greeting, so the reader must be retained for the connection.
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http connect: proxy responded with %q: %w", response.Status, ErrProxy)
+	}
+
+	return reader, nil
+}
+
+// dialProxy establishes the connection with a proxy server.
+func dialProxy(ctx context.Context, forward ContextDialer, network string, address string) (net.Conn, error) {
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+	return forward.DialContext(ctx, network, address)
+}
+
+// withContextDeadline runs a proxy handshake on conn such that the handshake
+// is interrupted when the context is done.
+func withContextDeadline(ctx context.Context, conn net.Conn, handshake func() error) error {
+	stop := context.AfterFunc(ctx, func() {
+		// Unblock any pending read or write.
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	err := handshake()
+	if !stop() || (err != nil && ctx.Err() != nil) {
+		// The context finished while the handshake was running. Report
+		// the reason instead of the resulting i/o error.
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// bufferedConn is a connection whose first bytes have already been read
+// into a buffer. Reads drain the buffer before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (bc *bufferedConn) Read(buf []byte) (int, error) {
+	return bc.reader.Read(buf)
+}
@@ -0,0 +1,343 @@
+package nntpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relay copies data between the two connections until either is closed.
+func relay(a net.Conn, b net.Conn, aReader io.Reader) {
+	go func() {
+		io.Copy(b, aReader)
+		b.Close()
+	}()
+	io.Copy(a, b)
+	a.Close()
+}
+
+// startSocks5Proxy starts a minimal SOCKS5 proxy. If auth is not nil, the
+// proxy requires username and password authentication. Requested addresses
+// are recorded to the returned channel.
+func startSocks5Proxy(t *testing.T, auth *ProxyAuth) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	requested := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				greeting := make([]byte, 2)
+				io.ReadFull(conn, greeting)
+				methods := make([]byte, greeting[1])
+				io.ReadFull(conn, methods)
+
+				if auth == nil {
+					conn.Write([]byte{0x05, 0x00})
+				} else {
+					conn.Write([]byte{0x05, 0x02})
+					header := make([]byte, 2)
+					io.ReadFull(conn, header)
+					user := make([]byte, header[1])
+					io.ReadFull(conn, user)
+					passLength := make([]byte, 1)
+					io.ReadFull(conn, passLength)
+					pass := make([]byte, passLength[0])
+					io.ReadFull(conn, pass)
+					if string(user) != auth.Username || string(pass) != auth.Password {
+						conn.Write([]byte{0x01, 0x01})
+						return
+					}
+					conn.Write([]byte{0x01, 0x00})
+				}
+
+				request := make([]byte, 4)
+				io.ReadFull(conn, request)
+				var host string
+				switch request[3] {
+				case 0x01:
+					ip := make([]byte, net.IPv4len)
+					io.ReadFull(conn, ip)
+					host = net.IP(ip).String()
+				case 0x03:
+					length := make([]byte, 1)
+					io.ReadFull(conn, length)
+					name := make([]byte, length[0])
+					io.ReadFull(conn, name)
+					host = string(name)
+				}
+				port := make([]byte, 2)
+				io.ReadFull(conn, port)
+				address := net.JoinHostPort(host, cast.ToString(binary.BigEndian.Uint16(port)))
+				requested <- address
+
+				target, err := net.Dial("tcp", address)
+				if err != nil {
+					conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+				relay(conn, target, conn)
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String(), requested
+}
+
+// startHttpProxy starts a minimal HTTP proxy supporting only the `CONNECT`
+// method. If auth is not nil, the proxy requires basic authentication.
+func startHttpProxy(t *testing.T, auth *ProxyAuth) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+				request, err := http.ReadRequest(reader)
+				if err != nil || request.Method != http.MethodConnect {
+					io.WriteString(conn, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+					return
+				}
+				if auth != nil {
+					credentials := auth.Username + ":" + auth.Password
+					expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+					if request.Header.Get("Proxy-Authorization") != expected {
+						io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+						return
+					}
+				}
+
+				target, err := net.Dial("tcp", request.Host)
+				if err != nil {
+					io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+					return
+				}
+				io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				relay(conn, target, reader)
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// startTLSTestServer starts a TLS enabled server that greets clients and
+// answers `DATE` commands.
+func startTLSTestServer(t *testing.T) (string, int) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{fakeCert},
+	})
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				writeLines(conn, "200 welcome")
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					writeLines(conn, "111 20231112130000")
+				}
+			}(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, cast.ToInt(port)
+}
+
+func dateHandler(t *testing.T, c net.Conn, cmd string, params []string) {
+	writeLines(c, "111 20231112130000")
+}
+
+func Test_Socks5Dialer(t *testing.T) {
+	expectedDate := time.Date(2023, 11, 12, 13, 0, 0, 0, time.UTC)
+
+	t.Run("connects without authentication", func(t *testing.T) {
+		server, err := NewTestServer(t, dateHandler)
+		require.Nil(t, err)
+		defer server.Close()
+		proxy, requested := startSocks5Proxy(t, nil)
+
+		client, err := NewWithPort("127.0.0.1", server.Port, WithSocks5Proxy(proxy, nil))
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		assert.Equal(t, net.JoinHostPort("127.0.0.1", cast.ToString(server.Port)), <-requested)
+		date, err := client.Date()
+		assert.Nil(t, err)
+		assert.Equal(t, expectedDate, date)
+	})
+
+	t.Run("connects with authentication and host names", func(t *testing.T) {
+		server, err := NewTestServer(t, dateHandler)
+		require.Nil(t, err)
+		defer server.Close()
+		auth := &ProxyAuth{Username: "user", Password: "pass"}
+		proxy, requested := startSocks5Proxy(t, auth)
+
+		client, err := NewWithPort("localhost", server.Port, WithSocks5Proxy(proxy, auth))
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		assert.Equal(t, net.JoinHostPort("localhost", cast.ToString(server.Port)), <-requested)
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("connects to tls servers", func(t *testing.T) {
+		host, port := startTLSTestServer(t)
+		proxy, _ := startSocks5Proxy(t, nil)
+
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{InsecureSkipVerify: true}),
+			WithSocks5Proxy(proxy, nil),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		_, isTls := client.conn.(*tls.Conn)
+		assert.Equal(t, true, isTls)
+		date, err := client.Date()
+		assert.Nil(t, err)
+		assert.Equal(t, expectedDate, date)
+	})
+
+	t.Run("reports failed authentication", func(t *testing.T) {
+		proxy, _ := startSocks5Proxy(t, &ProxyAuth{Username: "user", Password: "pass"})
+		dialer := &Socks5Dialer{
+			ProxyAddress: proxy,
+			Auth:         &ProxyAuth{Username: "user", Password: "wrong"},
+		}
+
+		conn, err := dialer.DialContext(context.Background(), "tcp", "127.0.0.1:119")
+		assert.Nil(t, conn)
+		assert.Equal(t, true, errors.Is(err, ErrProxy))
+		assert.ErrorContains(t, err, "socks5: authentication failed")
+	})
+
+	t.Run("reports missing authentication", func(t *testing.T) {
+		proxy, _ := startSocks5Proxy(t, &ProxyAuth{Username: "user", Password: "pass"})
+		dialer := &Socks5Dialer{ProxyAddress: proxy}
+
+		_, err := dialer.DialContext(context.Background(), "tcp", "127.0.0.1:119")
+		assert.ErrorContains(t, err, "socks5: no acceptable authentication method")
+	})
+
+	t.Run("reports refused connections", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+		proxy, _ := startSocks5Proxy(t, nil)
+		dialer := &Socks5Dialer{ProxyAddress: proxy}
+
+		_, err = dialer.DialContext(context.Background(), "tcp", address)
+		assert.Equal(t, true, errors.Is(err, ErrProxy))
+		assert.ErrorContains(t, err, "socks5: connection refused")
+	})
+
+	t.Run("honors context deadlines", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer listener.Close()
+		go func() {
+			// Accept, but never answer the greeting.
+			conn, _ := listener.Accept()
+			if conn != nil {
+				defer conn.Close()
+				time.Sleep(time.Second)
+			}
+		}()
+		dialer := &Socks5Dialer{ProxyAddress: listener.Addr().String()}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = dialer.DialContext(ctx, "tcp", "127.0.0.1:119")
+		assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func Test_HttpConnectDialer(t *testing.T) {
+	t.Run("connects without authentication", func(t *testing.T) {
+		server, err := NewTestServer(t, dateHandler)
+		require.Nil(t, err)
+		defer server.Close()
+		proxy := startHttpProxy(t, nil)
+
+		client, err := NewWithPort("127.0.0.1", server.Port, WithHttpConnectProxy(proxy, nil))
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("connects to tls servers with authentication", func(t *testing.T) {
+		host, port := startTLSTestServer(t)
+		auth := &ProxyAuth{Username: "user", Password: "pass"}
+		proxy := startHttpProxy(t, auth)
+
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{InsecureSkipVerify: true}),
+			WithHttpConnectProxy(proxy, auth),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("reports proxy errors", func(t *testing.T) {
+		proxy := startHttpProxy(t, &ProxyAuth{Username: "user", Password: "pass"})
+		dialer := &HttpConnectDialer{ProxyAddress: proxy}
+
+		conn, err := dialer.DialContext(context.Background(), "tcp", "127.0.0.1:119")
+		assert.Nil(t, conn)
+		assert.Equal(t, true, errors.Is(err, ErrProxy))
+		assert.ErrorContains(t, err, `http connect: proxy responded with "407 Proxy Authentication Required"`)
+	})
+}
@@ -1,18 +1,42 @@
 package nntpclient
 
-// Close is an alias for the Quit method.
+// Close terminates the connection to the server, sending a `QUIT` first if
+// the client is connected. Unlike [Client.Quit], Close is idempotent: it may
+// be invoked on a client that was never connected, or that has already been
+// closed, and will return nil in those cases.
 func (c *Client) Close() error {
-	return c.Quit()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateClosed || c.conn == nil {
+		c.state = StateClosed
+		return nil
+	}
+	return c.quit()
 }
 
 // Quit sends a standard `QUIT` to the remote server and terminates
-// the connection.
+// the connection. The connection is terminated, and the client is
+// considered closed, even if the server does not respond as expected.
 func (c *Client) Quit() error {
-	_, _, err := c.sendCommand("QUIT")
-	if err != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.quit()
+}
+
+func (c *Client) quit() error {
+	if err := c.ensureOpen("QUIT"); err != nil {
 		return err
 	}
 
+	_, _, err := c.sendCommand("QUIT")
 	c.conn.Close()
-	return nil
+	c.state = StateClosed
+	if c.keepaliveStop != nil {
+		close(c.keepaliveStop)
+		c.keepaliveStop = nil
+	}
+
+	return err
 }
@@ -1,6 +1,7 @@
 package nntpclient
 
 import (
+	"errors"
 	"net"
 	"testing"
 
@@ -22,6 +23,7 @@ func Test_Quit(t *testing.T) {
 
 		err := client.Quit()
 		assert.ErrorContains(t, err, "invalid syntax")
+		assert.Equal(t, StateClosed, client.State())
 	})
 
 	t.Run("handles success", func(t *testing.T) {
@@ -30,6 +32,25 @@ func Test_Quit(t *testing.T) {
 
 		err := client.Quit()
 		assert.Nil(t, err)
+		assert.Equal(t, StateClosed, client.State())
+	})
+
+	t.Run("quit on closed client returns error", func(t *testing.T) {
+		server, client := getServerAndClient(t, successHandler)
+		defer server.Close()
+
+		err := client.Quit()
+		assert.Nil(t, err)
+
+		err = client.Quit()
+		assert.Equal(t, true, errors.Is(err, ErrClientClosed))
+	})
+
+	t.Run("quit before connect returns error", func(t *testing.T) {
+		client := Client{}
+		err := client.Quit()
+		assert.Equal(t, true, errors.Is(err, ErrNotConnected))
+		assert.Equal(t, StateNew, client.State())
 	})
 
 	t.Run("close alias succeeds", func(t *testing.T) {
@@ -38,5 +59,21 @@ func Test_Quit(t *testing.T) {
 
 		err := client.Close()
 		assert.Nil(t, err)
+		assert.Equal(t, StateClosed, client.State())
+	})
+
+	t.Run("close is idempotent", func(t *testing.T) {
+		server, client := getServerAndClient(t, successHandler)
+		defer server.Close()
+
+		assert.Nil(t, client.Close())
+		assert.Nil(t, client.Close())
+		assert.Equal(t, StateClosed, client.State())
+	})
+
+	t.Run("close before connect succeeds", func(t *testing.T) {
+		client := Client{}
+		assert.Nil(t, client.Close())
+		assert.Equal(t, StateClosed, client.State())
 	})
 }
@@ -3,6 +3,8 @@ package nntpclient
 import (
 	"bufio"
 	"io"
+	"net"
+	"time"
 )
 
 // MultibyteReader is an interface for readers that support reading
@@ -15,7 +17,7 @@ type Response struct {
 	bufferedReader *bufio.Reader
 }
 
-func NewResponse(conn io.ReadWriteCloser) *Response {
+func NewResponse(conn io.Reader) *Response {
 	return &Response{
 		bufferedReader: bufio.NewReader(conn),
 	}
@@ -24,3 +26,18 @@ func NewResponse(conn io.ReadWriteCloser) *Response {
 func (r *Response) ReadBytes(delim byte) ([]byte, error) {
 	return r.bufferedReader.ReadBytes(delim)
 }
+
+// idleReader refreshes the read deadline of the underlying connection before
+// every read when a timeout has been set. Thus, the deadline bounds the gap
+// between received bytes instead of the duration of the whole response.
+type idleReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(buf []byte) (int, error) {
+	if r.timeout > 0 {
+		r.conn.SetReadDeadline(time.Now().Add(r.timeout))
+	}
+	return r.conn.Read(buf)
+}
@@ -1,17 +1,52 @@
 package nntpclient
 
 import (
+	"context"
 	"crypto/tls"
 )
 
+// WithRequireTls prevents [Client.Authenticate] from sending credentials
+// over a connection that is not protected by TLS, either by connecting to a
+// TLS enabled port or by upgrading the connection with [Client.StartTLS].
+// Attempting to authenticate over a plain text connection results in
+// [ErrTlsRequired].
+func WithRequireTls() Option {
+	return func(client *Client) {
+		client.requireTls = true
+	}
+}
+
+// isTls indicates if the connection is protected by TLS.
+func (c *Client) isTls() bool {
+	_, ok := c.conn.(*tls.Conn)
+	return ok
+}
+
 // StartTLS upgrades the current connection to a TLS protected one.
 // See RFC 4642.
 //
+// The upgrade must be performed prior to authenticating, and may only be
+// performed once. Upon a successful upgrade, any capabilities retrieved
+// prior to the upgrade are discarded. If the TLS handshake fails, the
+// connection is terminated and the client is considered closed.
+//
 // Note: if a config is not provided, one with the `ServerName` set to the
-// host will be used.
+// host will be used. Client certificates and public key pins configured with
+// [WithClientCertificate] and [WithPinnedPublicKeys] are applied to the
+// config.
 func (c *Client) StartTLS(config *tls.Config) error {
-	// This implementation is based upon
-	// https://cs.opensource.google/go/go/+/refs/tags/go1.21.3:src/net/smtp/smtp.go;l=154-166
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateAuthenticated {
+		return &StateError{Op: "STARTTLS", State: c.state, Err: ErrAlreadyAuthenticated}
+	}
+	if c.isTls() {
+		return ErrTlsActive
+	}
+	if err := c.requireCapability("STARTTLS"); err != nil {
+		return err
+	}
 
 	if config == nil {
 		config = &tls.Config{ServerName: c.host}
@@ -25,14 +60,26 @@ func (c *Client) StartTLS(config *tls.Config) error {
 		return UnexpectedError(code, message)
 	}
 
-	c.conn = tls.Client(c.conn, config)
+	ctx := context.Background()
+	if c.commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.commandTimeout)
+		defer cancel()
+	}
 
-	// Verify that the upgrade has worked. If we get an error, it's likely
-	// a certificate error.
-	_, err = c.Date()
-	if err != nil {
+	tlsConn := tls.Client(c.conn, c.prepareTlsConfig(config))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		c.conn.Close()
+		c.state = StateClosed
 		return err
 	}
 
+	// Anything buffered from the plain text connection must not be
+	// interpreted as part of the protected session.
+	c.conn = tlsConn
+	c.dataReader = &idleReader{conn: c.conn}
+	c.currentResponse = NewResponse(c.dataReader)
+	c.invalidateCapabilities()
+
 	return nil
 }
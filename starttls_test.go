@@ -3,10 +3,12 @@ package nntpclient
 import (
 	"bufio"
 	"crypto/tls"
+	"errors"
 	"io"
 	"net"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +38,27 @@ func Test_StartTLS(t *testing.T) {
 
 		err := client.StartTLS(nil)
 		assert.ErrorContains(t, err, "first record does not look like a TLS")
+		assert.Equal(t, StateClosed, client.state)
+	})
+
+	t.Run("rejects upgrade after authentication", func(t *testing.T) {
+		client := Client{
+			conn:  responseConn{response: &singleLineReader{line: "382 starttls\r\n"}},
+			state: StateAuthenticated,
+		}
+
+		err := client.StartTLS(nil)
+		assert.Equal(t, true, errors.Is(err, ErrAlreadyAuthenticated))
+	})
+
+	t.Run("rejects upgrade of a tls connection", func(t *testing.T) {
+		client := Client{
+			conn:  tls.Client(responseConn{}, &tls.Config{}),
+			state: StateConnected,
+		}
+
+		err := client.StartTLS(nil)
+		assert.Equal(t, true, errors.Is(err, ErrTlsActive))
 	})
 
 	t.Run("handles unexpected response code", func(t *testing.T) {
@@ -96,9 +119,18 @@ func Test_StartTLS(t *testing.T) {
 		require.Nil(t, err)
 		defer conn.Close()
 
-		client := Client{conn: conn}
+		client := Client{
+			conn:               conn,
+			cachedCapabilities: Capabilities{"STARTTLS": {}},
+		}
 
 		err = client.StartTLS(&tls.Config{InsecureSkipVerify: true})
 		assert.Nil(t, err)
+		assert.Equal(t, true, client.isTls())
+		assert.Nil(t, client.cachedCapabilities)
+
+		date, err := client.Date()
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, 11, 12, 13, 0, 0, 0, time.UTC), date)
 	})
 }
@@ -2,9 +2,6 @@ package nntpclient
 
 import (
 	"fmt"
-	"strings"
-
-	"github.com/spf13/cast"
 )
 
 // Stat is used to determine if an article exists. It works like [Article]
@@ -12,9 +9,21 @@ import (
 // message id.
 //
 // If the article exists, its internal group id and global message id is
-// returned. Otherwise, an error is returned along with `-1` and an empty
-// string.
+// returned, and unless it was requested by message id, it becomes the current
+// article, see [Client.CurrentArticle]. Otherwise, an error is returned along
+// with `-1` and an empty string.
 func (c *Client) Stat(id string) (int, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stat(id)
+}
+
+func (c *Client) stat(id string) (int, string, error) {
+	if err := c.requireCapability("READER"); err != nil {
+		return -1, "", err
+	}
+
 	var cmd string
 	if id == "" {
 		cmd = "STAT"
@@ -42,7 +51,11 @@ func (c *Client) Stat(id string) (int, string, error) {
 		return -1, "", UnexpectedError(code, message)
 	}
 
-	parts := strings.Fields(message)
+	number, messageId, err := parseArticleResponse(message)
+	if err != nil {
+		return -1, "", err
+	}
+	c.trackArticle(id, message)
 
-	return cast.ToInt(parts[0]), parts[1], nil
+	return number, messageId, nil
 }
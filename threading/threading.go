@@ -0,0 +1,340 @@
+// Package threading reconstructs conversation threads from the message ids,
+// `References` headers, and subjects of articles using the algorithm
+// described by Jamie Zawinski at https://www.jwz.org/doc/threading.html.
+//
+// The input is typically obtained from the headers returned by
+// [nntpclient.Client.Head], see [FromHeaders], or from overview data:
+//
+//	var messages []*threading.Message
+//	for _, id := range ids {
+//		headers, err := client.Head(id)
+//		// ...
+//		messages = append(messages, threading.FromHeaders(headers))
+//	}
+//	for _, root := range threading.Thread(messages) {
+//		root.Walk(func(c *threading.Container, depth int) {
+//			// ...
+//		})
+//	}
+package threading
+
+import (
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/popnzb/nntpclient"
+)
+
+// Message is the information about an article that is needed to thread it.
+type Message struct {
+	// Id is the message id of the article, including the angle brackets.
+	Id string
+	// References are the message ids of the ancestors of the article, oldest
+	// first, as given by the `References` header.
+	References []string
+	Subject    string
+	// Date is used to order the articles within a thread. It may be zero.
+	Date time.Time
+	// Value may hold arbitrary data of the caller, e.g. the article number.
+	Value any
+}
+
+// FromHeaders creates a [Message] from the headers of an article, e.g. those
+// returned by [nntpclient.Client.Head].
+func FromHeaders(headers textproto.MIMEHeader) *Message {
+	return FromArticle(nntpclient.ParseArticle(headers, nil))
+}
+
+// FromArticle creates a [Message] from a parsed article. If the article does
+// not have a `References` header, the first message id of the `In-Reply-To`
+// header, if any, is used instead.
+func FromArticle(article *nntpclient.Article) *Message {
+	references := article.References
+	if len(references) == 0 {
+		// In-Reply-To may contain free text besides the message id.
+		inReplyTo := article.Headers.Get("In-Reply-To")
+		start := strings.IndexByte(inReplyTo, '<')
+		end := strings.IndexByte(inReplyTo, '>')
+		if start >= 0 && end > start {
+			references = []string{inReplyTo[start : end+1]}
+		}
+	}
+
+	return &Message{
+		Id:         article.MessageId,
+		References: references,
+		Subject:    article.Subject,
+		Date:       article.Date,
+	}
+}
+
+// Container is a node of a thread. Containers without a message, i.e. dummy
+// containers, stand in for articles that are referenced but not available,
+// or group articles that share a subject.
+type Container struct {
+	Message  *Message
+	Parent   *Container
+	Children []*Container
+}
+
+// IsDummy indicates if the container does not hold a message.
+func (c *Container) IsDummy() bool {
+	return c.Message == nil
+}
+
+// Walk invokes fn for the container and all of its descendants, depth first.
+// The depth of the container itself is 0.
+func (c *Container) Walk(fn func(c *Container, depth int)) {
+	c.walk(fn, 0)
+}
+
+func (c *Container) walk(fn func(c *Container, depth int), depth int) {
+	fn(c, depth)
+	for _, child := range c.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Subject returns the subject of the message of the container or, for a
+// dummy container, that of its first child.
+func (c *Container) Subject() string {
+	if c.Message != nil {
+		return c.Message.Subject
+	}
+	if len(c.Children) > 0 {
+		return c.Children[0].Subject()
+	}
+	return ""
+}
+
+// Date returns the date of the message of the container or, for a dummy
+// container, the earliest date of its children.
+func (c *Container) Date() time.Time {
+	if c.Message != nil {
+		return c.Message.Date
+	}
+	var earliest time.Time
+	for _, child := range c.Children {
+		date := child.Date()
+		if !date.IsZero() && (earliest.IsZero() || date.Before(earliest)) {
+			earliest = date
+		}
+	}
+	return earliest
+}
+
+// hasDescendant indicates if other is the container itself or one of its
+// descendants.
+func (c *Container) hasDescendant(other *Container) bool {
+	for ; other != nil; other = other.Parent {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Container) addChild(child *Container) {
+	if child.Parent != nil {
+		child.Parent.removeChild(child)
+	}
+	child.Parent = c
+	c.Children = append(c.Children, child)
+}
+
+func (c *Container) removeChild(child *Container) {
+	if i := slices.Index(c.Children, child); i >= 0 {
+		c.Children = slices.Delete(c.Children, i, i+1)
+	}
+	child.Parent = nil
+}
+
+// Thread arranges the messages into threads and returns the roots of the
+// threads. Roots and siblings are ordered by date. Containers without a date
+// follow those with a date in the order of the input.
+func Thread(messages []*Message) []*Container {
+	table := make(map[string]*Container)
+	// ordered retains the order in which containers are created, so that the
+	// result does not depend on the iteration order of the table.
+	var ordered []*Container
+	get := func(id string) *Container {
+		container, found := table[id]
+		if !found {
+			container = &Container{}
+			table[id] = container
+			ordered = append(ordered, container)
+		}
+		return container
+	}
+
+	for _, message := range messages {
+		container := get(message.Id)
+		if container.Message != nil {
+			// Duplicate message ids are threaded as distinct messages.
+			container = &Container{}
+			ordered = append(ordered, container)
+		}
+		container.Message = message
+
+		// Link the references together in order, unless they are already
+		// linked or linking them would introduce a loop.
+		var parent *Container
+		for _, id := range message.References {
+			ref := get(id)
+			if parent != nil && ref.Parent == nil && !ref.hasDescendant(parent) {
+				parent.addChild(ref)
+			}
+			parent = ref
+		}
+
+		// The references of the message itself are authoritative for its
+		// parent.
+		if container.Parent != nil {
+			container.Parent.removeChild(container)
+		}
+		if parent != nil && !container.hasDescendant(parent) {
+			parent.addChild(container)
+		}
+	}
+
+	var roots []*Container
+	for _, container := range ordered {
+		if container.Parent == nil {
+			roots = append(roots, container)
+		}
+	}
+
+	root := &Container{}
+	for _, container := range roots {
+		root.addChild(container)
+	}
+	prune(root, true)
+	gatherBySubject(root)
+	sortByDate(root)
+
+	for _, container := range root.Children {
+		container.Parent = nil
+	}
+	return root.Children
+}
+
+// prune removes dummy containers without children, and replaces dummy
+// containers with their children. At the root level, dummy containers are
+// only replaced if they have a single child, so that the articles that
+// reference the same missing article remain grouped.
+func prune(c *Container, isRoot bool) {
+	var children []*Container
+	for _, child := range c.Children {
+		prune(child, false)
+
+		switch {
+		case child.IsDummy() && len(child.Children) == 0:
+			continue
+		case child.IsDummy() && (!isRoot || len(child.Children) == 1):
+			for _, grandchild := range child.Children {
+				grandchild.Parent = c
+			}
+			children = append(children, child.Children...)
+		default:
+			children = append(children, child)
+		}
+	}
+	c.Children = children
+}
+
+// replyPrefix matches prefixes that mark replies, e.g. `Re:`, `RE[2]:`, and
+// `Re^3:`.
+var replyPrefix = regexp.MustCompile(`(?i)^\s*re(\[\d+\]|\^\d+)?\s*:\s*`)
+
+// baseSubject strips all reply prefixes from the subject. The second result
+// indicates if there were any.
+func baseSubject(subject string) (string, bool) {
+	reply := false
+	for {
+		location := replyPrefix.FindStringIndex(subject)
+		if location == nil {
+			return strings.TrimSpace(subject), reply
+		}
+		subject = subject[location[1]:]
+		reply = true
+	}
+}
+
+func isReply(c *Container) bool {
+	_, reply := baseSubject(c.Subject())
+	return reply
+}
+
+// gatherBySubject merges the threads at the root level that share a subject,
+// but were not connected by their references.
+func gatherBySubject(root *Container) {
+	table := make(map[string]*Container)
+	for _, c := range root.Children {
+		subject, _ := baseSubject(c.Subject())
+		if subject == "" {
+			continue
+		}
+		existing, found := table[subject]
+		if !found ||
+			(c.IsDummy() && !existing.IsDummy()) ||
+			(!isReply(c) && isReply(existing) && !existing.IsDummy()) {
+			table[subject] = c
+		}
+	}
+
+	for _, c := range slices.Clone(root.Children) {
+		subject, _ := baseSubject(c.Subject())
+		other, found := table[subject]
+		if subject == "" || !found || other == c {
+			continue
+		}
+
+		// A dummy container, if any, is in the table. Otherwise, the container
+		// in the table is not a reply, unless all of them are.
+		switch {
+		case c.IsDummy() && other.IsDummy():
+			for _, child := range slices.Clone(c.Children) {
+				other.addChild(child)
+			}
+			root.removeChild(c)
+		case other.IsDummy():
+			other.addChild(c)
+		case !isReply(other) && isReply(c):
+			other.addChild(c)
+		default:
+			// Both are replies, or neither is. Neither is the parent of the
+			// other, so both become children of a new dummy.
+			dummy := &Container{}
+			index := slices.Index(root.Children, other)
+			root.removeChild(other)
+			root.Children = slices.Insert(root.Children, index, dummy)
+			dummy.Parent = root
+			dummy.addChild(other)
+			dummy.addChild(c)
+			table[subject] = dummy
+		}
+	}
+}
+
+// sortByDate orders the children of c, and their descendants, by date.
+// Containers without a date follow those with a date.
+func sortByDate(c *Container) {
+	slices.SortStableFunc(c.Children, func(a *Container, b *Container) int {
+		dateA, dateB := a.Date(), b.Date()
+		switch {
+		case dateA.IsZero() && dateB.IsZero():
+			return 0
+		case dateA.IsZero():
+			return 1
+		case dateB.IsZero():
+			return -1
+		}
+		return dateA.Compare(dateB)
+	})
+	for _, child := range c.Children {
+		sortByDate(child)
+	}
+}
@@ -0,0 +1,242 @@
+package threading
+
+import (
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func message(id string, subject string, references ...string) *Message {
+	return &Message{Id: "<" + id + ">", Subject: subject, References: brackets(references)}
+}
+
+func brackets(ids []string) []string {
+	var result []string
+	for _, id := range ids {
+		result = append(result, "<"+id+">")
+	}
+	return result
+}
+
+// render draws the threads with one line per container, indented by depth.
+// Dummy containers are drawn as `*`.
+func render(roots []*Container) string {
+	var lines []string
+	for _, root := range roots {
+		root.Walk(func(c *Container, depth int) {
+			name := "*"
+			if !c.IsDummy() {
+				name = strings.Trim(c.Message.Id, "<>")
+			}
+			lines = append(lines, strings.Repeat("  ", depth)+name)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Test_Thread(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []*Message
+		expected string
+	}{
+		{
+			name: "links references",
+			messages: []*Message{
+				message("c", "Re: a", "a", "b"),
+				message("a", "a"),
+				message("b", "Re: a", "a"),
+				message("d", "Re: a", "a"),
+			},
+			expected: "a\n  b\n    c\n  d",
+		},
+		{
+			name: "keeps dummies for shared missing parents",
+			messages: []*Message{
+				message("b", "Re: x", "x"),
+				message("c", "Re: x", "x"),
+				message("d", "unrelated"),
+			},
+			expected: "*\n  b\n  c\nd",
+		},
+		{
+			name: "removes dummies with a single child",
+			messages: []*Message{
+				message("b", "Re: x", "x"),
+			},
+			expected: "b",
+		},
+		{
+			name: "removes dummies below the root level",
+			messages: []*Message{
+				message("a", "a"),
+				message("c", "Re: a", "a", "x", "y"),
+				message("d", "Re: a", "a", "x"),
+			},
+			expected: "a\n  c\n  d",
+		},
+		{
+			name: "trusts the references of the message itself",
+			messages: []*Message{
+				// The references of c claim that b is a child of x.
+				message("c", "c", "x", "b"),
+				message("b", "b", "a"),
+				message("a", "a"),
+			},
+			expected: "a\n  b\n    c",
+		},
+		{
+			name: "avoids loops",
+			messages: []*Message{
+				message("a", "a", "b"),
+				message("b", "b", "a"),
+				message("c", "c", "c"),
+			},
+			expected: "b\n  a\nc",
+		},
+		{
+			name: "threads duplicate ids separately",
+			messages: []*Message{
+				message("a", "one"),
+				message("a", "two"),
+			},
+			expected: "a\na",
+		},
+		{
+			name: "gathers replies by subject",
+			messages: []*Message{
+				message("b", "Re: Hello"),
+				message("a", "Hello"),
+				message("c", "RE[2]: re: Hello"),
+			},
+			expected: "a\n  b\n  c",
+		},
+		{
+			name: "gathers equal subjects under a dummy",
+			messages: []*Message{
+				message("a", "Hello"),
+				message("b", "Hello"),
+				message("c", "Re: Other"),
+				message("d", "Re^2: Other"),
+				message("e", "Hello"),
+			},
+			expected: "*\n  a\n  b\n  e\n*\n  c\n  d",
+		},
+		{
+			name: "gathers into existing dummies",
+			messages: []*Message{
+				message("b", "Re: x", "x"),
+				message("c", "Re: x", "x"),
+				message("d", "x"),
+				message("f", "Re: x", "y"),
+				message("g", "Re: x", "y"),
+			},
+			expected: "*\n  b\n  c\n  d\n  f\n  g",
+		},
+		{
+			name: "does not gather empty subjects",
+			messages: []*Message{
+				message("a", ""),
+				message("b", "Re: "),
+			},
+			expected: "a\nb",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roots := Thread(test.messages)
+			assert.Equal(t, test.expected, render(roots))
+			for _, root := range roots {
+				assert.Nil(t, root.Parent)
+				root.Walk(func(c *Container, depth int) {
+					for _, child := range c.Children {
+						assert.Equal(t, c, child.Parent)
+					}
+				})
+			}
+		})
+	}
+}
+
+func Test_ThreadOrder(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dated := func(m *Message, hours int) *Message {
+		m.Date = base.Add(time.Duration(hours) * time.Hour)
+		return m
+	}
+
+	messages := []*Message{
+		message("undated", "undated"),
+		dated(message("late", "late"), 10),
+		dated(message("reply-2", "Re: early", "early"), 5),
+		dated(message("early", "early"), 1),
+		dated(message("reply-1", "Re: early", "early"), 3),
+		dated(message("orphan-2", "Re: missing", "missing"), 4),
+		dated(message("orphan-1", "Re: missing", "missing"), 2),
+	}
+
+	roots := Thread(messages)
+	assert.Equal(t, "early\n  reply-1\n  reply-2\n*\n  orphan-1\n  orphan-2\nlate\nundated", render(roots))
+	assert.Equal(t, base.Add(2*time.Hour), roots[1].Date())
+	assert.Equal(t, "Re: missing", roots[1].Subject())
+}
+
+func Test_FromHeaders(t *testing.T) {
+	t.Run("uses references", func(t *testing.T) {
+		headers := textproto.MIMEHeader{
+			"Message-Id":  {"<c@example>"},
+			"Subject":     {"=?UTF-8?Q?Re:_caf=C3=A9?="},
+			"Date":        {"Mon, 1 Jan 2024 10:00:00 +0000"},
+			"References":  {"<a@example> <b@example>"},
+			"In-Reply-To": {"<other@example>"},
+		}
+
+		expected := &Message{
+			Id:         "<c@example>",
+			References: []string{"<a@example>", "<b@example>"},
+			Subject:    "Re: café",
+			Date:       time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		}
+		actual := FromHeaders(headers)
+		assert.Equal(t, expected.Date.Unix(), actual.Date.Unix())
+		actual.Date = expected.Date
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("falls back to in-reply-to", func(t *testing.T) {
+		headers := textproto.MIMEHeader{
+			"Message-Id":  {"<c@example>"},
+			"In-Reply-To": {"Your message of Monday <b@example> (Alice)"},
+		}
+		assert.Equal(t, []string{"<b@example>"}, FromHeaders(headers).References)
+
+		headers.Set("In-Reply-To", "no id")
+		assert.Nil(t, FromHeaders(headers).References)
+	})
+}
+
+func Test_baseSubject(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		reply    bool
+	}{
+		{"Hello", "Hello", false},
+		{"  Hello ", "Hello", false},
+		{"Re: Hello", "Hello", true},
+		{"RE: re:Hello", "Hello", true},
+		{"Re[3]: Hello", "Hello", true},
+		{"Re^2 : Hello", "Hello", true},
+		{"Regarding: Hello", "Regarding: Hello", false},
+	}
+
+	for _, test := range tests {
+		subject, reply := baseSubject(test.input)
+		assert.Equal(t, test.expected, subject, test.input)
+		assert.Equal(t, test.reply, reply, test.input)
+	}
+}
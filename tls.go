@@ -0,0 +1,74 @@
+package nntpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// WithClientCertificate configures a certificate to present to the server
+// when it requests client authentication during the TLS handshake. The
+// certificate is used both when connecting to a TLS enabled port, e.g. via
+// [NewTls], and when upgrading a connection with [Client.StartTLS].
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(client *Client) {
+		client.clientCertificates = append(client.clientCertificates, cert)
+	}
+}
+
+// WithPinnedPublicKeys configures the SHA-256 hashes of the server public keys
+// that are trusted, see [PublicKeyHash]. When at least one hash is configured,
+// the server certificate chain is _not_ verified against the certificate
+// authorities. Instead, the handshake succeeds only if the public key of the
+// server certificate matches one of the hashes. Otherwise, the handshake
+// fails with [ErrCertificatePinMismatch].
+//
+// Pinning applies both when connecting to a TLS enabled port, e.g. via
+// [NewTls], and when upgrading a connection with [Client.StartTLS].
+func WithPinnedPublicKeys(hashes ...[]byte) Option {
+	return func(client *Client) {
+		client.pinnedPublicKeys = append(client.pinnedPublicKeys, hashes...)
+	}
+}
+
+// PublicKeyHash computes the SHA-256 hash of the DER encoded subject public
+// key info of the certificate. This is the value expected by
+// [WithPinnedPublicKeys].
+func PublicKeyHash(cert *x509.Certificate) []byte {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hash[:]
+}
+
+// prepareTlsConfig returns a copy of config completed with the client
+// TLS options: the server name, client certificates, and public key pins.
+func (c *Client) prepareTlsConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	if config.ServerName == "" {
+		config.ServerName = c.host
+	}
+	if len(c.clientCertificates) > 0 {
+		config.Certificates = append(config.Certificates, c.clientCertificates...)
+	}
+	if len(c.pinnedPublicKeys) > 0 {
+		// Chain verification is replaced by verifying the pins.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = c.verifyPinnedPublicKey
+	}
+	return config
+}
+
+func (c *Client) verifyPinnedPublicKey(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("server did not present a certificate: %w", ErrCertificatePinMismatch)
+	}
+
+	hash := PublicKeyHash(state.PeerCertificates[0])
+	for _, pin := range c.pinnedPublicKeys {
+		if bytes.Equal(hash, pin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("public key hash %x: %w", hash, ErrCertificatePinMismatch)
+}
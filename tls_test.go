@@ -0,0 +1,272 @@
+package nntpclient
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateCertificate creates a certificate for the given common name. If
+// parent is nil, the certificate is a self-signed certificate authority.
+// Otherwise, it is signed by parent.
+func generateCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.Nil(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// startCertServer starts a TLS server that greets clients and answers all
+// commands with a `DATE` response.
+func startCertServer(t *testing.T, config *tls.Config) (string, int) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				writeLines(conn, "200 welcome")
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					writeLines(conn, "111 20231112130000")
+				}
+			}(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, cast.ToInt(port)
+}
+
+func Test_PublicKeyHash(t *testing.T) {
+	cert := generateCertificate(t, "localhost", nil)
+	assert.Len(t, PublicKeyHash(cert.Leaf), 32)
+
+	other := generateCertificate(t, "localhost", nil)
+	assert.NotEqual(t, PublicKeyHash(cert.Leaf), PublicKeyHash(other.Leaf))
+}
+
+func Test_prepareTlsConfig(t *testing.T) {
+	cert := generateCertificate(t, "client", nil)
+	c, err := _new("news.example.com", 563, WithClientCertificate(cert), WithPinnedPublicKeys([]byte{1}))
+	require.Nil(t, err)
+
+	original := &tls.Config{}
+	config := c.prepareTlsConfig(original)
+	assert.Equal(t, "news.example.com", config.ServerName)
+	assert.Len(t, config.Certificates, 1)
+	assert.Equal(t, true, config.InsecureSkipVerify)
+	assert.NotNil(t, config.VerifyConnection)
+
+	// The original config must not be modified.
+	assert.Equal(t, "", original.ServerName)
+	assert.Len(t, original.Certificates, 0)
+	assert.Equal(t, false, original.InsecureSkipVerify)
+}
+
+func Test_PinnedPublicKeys(t *testing.T) {
+	serverCert := generateCertificate(t, "localhost", nil)
+	host, port := startCertServer(t, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+
+	t.Run("connects when the pin matches", func(t *testing.T) {
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{}),
+			WithPinnedPublicKeys([]byte("not it"), PublicKeyHash(serverCert.Leaf)),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("fails when the pin does not match", func(t *testing.T) {
+		other := generateCertificate(t, "localhost", nil)
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{}),
+			WithPinnedPublicKeys(PublicKeyHash(other.Leaf)),
+		)
+		require.Nil(t, err)
+
+		err = client.Connect()
+		assert.Equal(t, true, errors.Is(err, ErrCertificatePinMismatch))
+		assert.Equal(t, StateNew, client.State())
+	})
+
+	t.Run("fails without a pin for an untrusted certificate", func(t *testing.T) {
+		client, err := NewWithPort(host, port, WithTlsConfig(&tls.Config{}))
+		require.Nil(t, err)
+
+		err = client.Connect()
+		assert.ErrorContains(t, err, "certificate")
+	})
+}
+
+func Test_ClientCertificate(t *testing.T) {
+	serverCert := generateCertificate(t, "localhost", nil)
+	clientCA := generateCertificate(t, "client-ca", nil)
+	clientCert := generateCertificate(t, "client", &clientCA)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA.Leaf)
+	host, port := startCertServer(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	t.Run("presents the client certificate", func(t *testing.T) {
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{}),
+			WithPinnedPublicKeys(PublicKeyHash(serverCert.Leaf)),
+			WithClientCertificate(clientCert),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("is rejected without a client certificate", func(t *testing.T) {
+		client, err := NewWithPort(
+			host,
+			port,
+			WithTlsConfig(&tls.Config{}),
+			WithPinnedPublicKeys(PublicKeyHash(serverCert.Leaf)),
+		)
+		require.Nil(t, err)
+
+		// With TLS 1.3 the server reports the missing certificate after the
+		// client considers the handshake complete, i.e. on the first read.
+		err = client.Connect()
+		assert.ErrorContains(t, err, "certificate required")
+	})
+}
+
+func Test_StartTLSCertificateOptions(t *testing.T) {
+	serverCert := generateCertificate(t, "localhost", nil)
+	clientCA := generateCertificate(t, "client-ca", nil)
+	clientCert := generateCertificate(t, "client", &clientCA)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA.Leaf)
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				writeLines(conn, "200 welcome")
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					line := scanner.Text()
+					if strings.HasPrefix(line, "STARTTLS") {
+						io.WriteString(conn, "382 do upgrade\r\n")
+						conn = tls.Server(conn, serverConfig)
+						scanner = bufio.NewScanner(conn)
+						continue
+					}
+					writeLines(conn, "111 20231112130000")
+				}
+			}(conn)
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	t.Run("applies pins and client certificates", func(t *testing.T) {
+		client, err := NewWithPort(
+			host,
+			cast.ToInt(port),
+			WithPinnedPublicKeys(PublicKeyHash(serverCert.Leaf)),
+			WithClientCertificate(clientCert),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+		defer client.Close()
+
+		require.Nil(t, client.StartTLS(nil))
+		_, err = client.Date()
+		assert.Nil(t, err)
+	})
+
+	t.Run("fails when the pin does not match", func(t *testing.T) {
+		other := generateCertificate(t, "localhost", nil)
+		client, err := NewWithPort(
+			host,
+			cast.ToInt(port),
+			WithPinnedPublicKeys(PublicKeyHash(other.Leaf)),
+			WithClientCertificate(clientCert),
+		)
+		require.Nil(t, err)
+		require.Nil(t, client.Connect())
+
+		err = client.StartTLS(nil)
+		assert.Equal(t, true, errors.Is(err, ErrCertificatePinMismatch))
+		assert.Equal(t, StateClosed, client.State())
+	})
+}
@@ -0,0 +1,333 @@
+// Package uudecode decodes uuencoded and xxencoded files embedded in article
+// bodies, e.g. those retrieved with [nntpclient.Client.Body].
+//
+// A [Decoder] is used in the same manner as an [archive/tar.Reader]: each
+// invocation of [Decoder.Next] advances to the next `begin` line, after which
+// the decoder can be read to obtain the content of that file:
+//
+//	decoder := uudecode.NewDecoder(bytes.NewReader(body))
+//	for {
+//		header, err := decoder.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		// ...
+//		data, err := io.ReadAll(decoder)
+//		// ...
+//	}
+//
+// Posts that are split across several articles are decoded by concatenating
+// the bodies of all parts, in order, e.g. with [io.MultiReader]. Lines that
+// are not valid encoded lines, such as the text of intermediate articles, are
+// skipped.
+package uudecode
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+)
+
+// Encoding identifies the encoding of a file.
+type Encoding int
+
+const (
+	UuEncoding Encoding = iota
+	XxEncoding
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UuEncoding:
+		return "uuencode"
+	case XxEncoding:
+		return "xxencode"
+	}
+	return "unknown"
+}
+
+// ErrMissingEnd is returned when the input ends before the `end` line of the
+// current file.
+var ErrMissingEnd = errors.New("uudecode: missing end line")
+
+// Header describes a file found in the input.
+type Header struct {
+	// Name is the file name given on the `begin` line. It is not sanitized,
+	// i.e. it may be an absolute path or contain `..` elements.
+	Name     string
+	Mode     fs.FileMode
+	Encoding Encoding
+}
+
+// File is a decoded file, see [DecodeAll].
+type File struct {
+	Header
+	Data []byte
+}
+
+// alphabet maps the characters of an encoding to their six bit values. Other
+// characters map to -1.
+type alphabet [256]int8
+
+func newAlphabet(characters string) *alphabet {
+	var a alphabet
+	for i := range a {
+		a[i] = -1
+	}
+	for i := 0; i < len(characters); i++ {
+		a[characters[i]] = int8(i)
+	}
+	return &a
+}
+
+var alphabets = map[Encoding]*alphabet{
+	UuEncoding: func() *alphabet {
+		a := newAlphabet(" !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_")
+		// Many encoders use a backtick instead of a space to represent zero.
+		a['`'] = 0
+		return a
+	}(),
+	XxEncoding: newAlphabet("+-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"),
+}
+
+// Decoder finds and decodes the encoded files in its input.
+type Decoder struct {
+	reader   *bufio.Reader
+	header   *Header
+	alphabet *alphabet
+	buffer   []byte
+	done     bool
+	err      error
+}
+
+// NewDecoder creates a [Decoder] reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{reader: bufio.NewReader(r)}
+}
+
+// Next advances to the next file in the input. Any remaining content of the
+// current file is skipped. At the end of the input, [io.EOF] is returned.
+func (d *Decoder) Next() (*Header, error) {
+	for d.header != nil && !d.done && d.err == nil {
+		d.buffer = d.buffer[:0]
+		d.fill()
+	}
+	d.header = nil
+	d.alphabet = nil
+	d.buffer = d.buffer[:0]
+	d.done = false
+	d.err = nil
+
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		header, ok := parseBegin(line)
+		if !ok {
+			continue
+		}
+
+		d.header = header
+		// The encoding is detected by the first encoded line, which is decoded
+		// into the buffer.
+		d.fill()
+		if d.err != nil {
+			return nil, d.err
+		}
+		if d.alphabet == alphabets[XxEncoding] {
+			header.Encoding = XxEncoding
+		}
+		return header, nil
+	}
+}
+
+// Read reads the content of the current file. At the `end` line of the file,
+// [io.EOF] is returned. If the input ends before the `end` line,
+// [ErrMissingEnd] is returned.
+func (d *Decoder) Read(p []byte) (int, error) {
+	if d.header == nil {
+		return 0, io.EOF
+	}
+	for len(d.buffer) == 0 && !d.done && d.err == nil {
+		d.fill()
+	}
+	if len(d.buffer) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		return 0, io.EOF
+	}
+
+	n := copy(p, d.buffer)
+	d.buffer = d.buffer[n:]
+	return n, nil
+}
+
+// fill reads lines until one has been decoded into the buffer, or the end of
+// the current file has been reached.
+func (d *Decoder) fill() {
+	for {
+		line, err := d.readLine()
+		if err == io.EOF {
+			d.err = ErrMissingEnd
+			return
+		}
+		if err != nil {
+			d.err = err
+			return
+		}
+
+		if string(bytes.TrimRight(line, " \t")) == "end" {
+			d.done = true
+			return
+		}
+
+		if d.alphabet == nil {
+			for _, encoding := range []Encoding{UuEncoding, XxEncoding} {
+				if decoded, ok := decodeStuffedLine(alphabets[encoding], line, d.buffer); ok {
+					d.alphabet = alphabets[encoding]
+					d.buffer = decoded
+					return
+				}
+			}
+			continue
+		}
+
+		if decoded, ok := decodeStuffedLine(d.alphabet, line, d.buffer); ok {
+			d.buffer = decoded
+			return
+		}
+	}
+}
+
+// readLine reads the next line without its line terminator.
+func (d *Decoder) readLine() ([]byte, error) {
+	line, err := d.reader.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// parseBegin parses a line such as `begin 644 file.bin`.
+func parseBegin(line []byte) (*Header, bool) {
+	rest, found := bytes.CutPrefix(line, []byte("begin "))
+	if !found {
+		return nil, false
+	}
+	mode, name, found := bytes.Cut(bytes.TrimLeft(rest, " "), []byte(" "))
+	if !found || len(mode) < 3 || len(mode) > 4 {
+		return nil, false
+	}
+	perm, err := strconv.ParseUint(string(mode), 8, 32)
+	if err != nil {
+		return nil, false
+	}
+	name = bytes.TrimSpace(name)
+	if len(name) == 0 {
+		return nil, false
+	}
+	return &Header{Name: string(name), Mode: fs.FileMode(perm).Perm()}, true
+}
+
+// decodeStuffedLine decodes a line that may be dot-stuffed. Lines that start
+// with a dot are dot-stuffed in transit, see RFC 3977 §3.1.1, and the stuffing
+// is not removed by [nntpclient.ReadBody].
+func decodeStuffedLine(a *alphabet, line []byte, dst []byte) ([]byte, bool) {
+	if bytes.HasPrefix(line, []byte("..")) {
+		if decoded, ok := decodeLine(a, line[1:], dst); ok {
+			return decoded, true
+		}
+	}
+	return decodeLine(a, line, dst)
+}
+
+// decodeLine decodes an encoded line and appends the result to dst. The first
+// character encodes the number of bytes on the line. Lines whose length does
+// not match that number, or which contain characters outside of the alphabet,
+// are not valid.
+func decodeLine(a *alphabet, line []byte, dst []byte) ([]byte, bool) {
+	if len(line) == 0 || a[line[0]] < 0 {
+		return dst, false
+	}
+	count := int(a[line[0]])
+	data := line[1:]
+	if a[' '] < 0 {
+		// Whitespace is never part of the encoded data.
+		data = bytes.TrimRight(data, " \t")
+	}
+
+	// The minimum number of characters required for count bytes, and the
+	// number used when every group of three bytes is encoded completely. Some
+	// encoders add a checksum character after the groups.
+	minimum := (count*4 + 2) / 3
+	complete := (count + 2) / 3 * 4
+	if len(data) > complete+1 {
+		// Trailing whitespace may have been added in transit.
+		data = bytes.TrimRight(data, " \t")
+		if len(data) > complete+1 {
+			return dst, false
+		}
+	}
+	if len(data) < minimum {
+		// Trailing spaces, which encode zero in uuencode, may have been
+		// stripped in transit. More than a single group is unlikely.
+		if a[' '] != 0 || minimum-len(data) > 4 {
+			return dst, false
+		}
+		data = append(bytes.Clone(data), bytes.Repeat([]byte(" "), minimum-len(data))...)
+	}
+
+	for _, c := range data {
+		if a[c] < 0 {
+			return dst, false
+		}
+	}
+
+	for i := 0; count > 0; i += 4 {
+		var group [4]byte
+		for j := 0; j < 4 && i+j < len(data); j++ {
+			group[j] = byte(a[data[i+j]])
+		}
+		decoded := []byte{
+			group[0]<<2 | group[1]>>4,
+			group[1]<<4 | group[2]>>2,
+			group[2]<<6 | group[3],
+		}
+		n := min(count, 3)
+		dst = append(dst, decoded[:n]...)
+		count -= n
+	}
+	return dst, true
+}
+
+// DecodeAll decodes every file in the input. Files that are not terminated by
+// an `end` line are returned with the data decoded so far, along with
+// [ErrMissingEnd].
+func DecodeAll(r io.Reader) ([]*File, error) {
+	var files []*File
+	decoder := NewDecoder(r)
+	for {
+		header, err := decoder.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+
+		data, err := io.ReadAll(decoder)
+		files = append(files, &File{Header: *header, Data: data})
+		if err != nil {
+			return files, fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package uudecode
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	uuCharacters = "`!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_"
+	xxCharacters = "+-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// encode encodes data with the given alphabet into lines of at most 45 bytes,
+// including the terminating zero length line.
+func encode(characters string, data []byte) []string {
+	var lines []string
+	for {
+		n := min(len(data), 45)
+		line := []byte{characters[n]}
+		for i := 0; i < n; i += 3 {
+			var group [3]byte
+			copy(group[:], data[i:min(i+3, n)])
+			line = append(line,
+				characters[group[0]>>2],
+				characters[(group[0]<<4|group[1]>>4)&0x3f],
+				characters[(group[1]<<2|group[2]>>6)&0x3f],
+				characters[group[2]&0x3f],
+			)
+		}
+		lines = append(lines, string(line))
+		if n == 0 {
+			return lines
+		}
+		data = data[n:]
+	}
+}
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func join(lines ...string) io.Reader {
+	return strings.NewReader(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func Test_Decoder(t *testing.T) {
+	t.Run("decodes a known file", func(t *testing.T) {
+		files, err := DecodeAll(join("begin 644 cat.txt", "#0V%T", "`", "end"))
+		require.Nil(t, err)
+		require.Len(t, files, 1)
+
+		assert.Equal(t, "cat.txt", files[0].Name)
+		assert.Equal(t, fs.FileMode(0644), files[0].Mode)
+		assert.Equal(t, UuEncoding, files[0].Encoding)
+		assert.Equal(t, "Cat", string(files[0].Data))
+	})
+
+	t.Run("decodes multiple files and encodings", func(t *testing.T) {
+		one := testData(100)
+		two := testData(200)
+
+		lines := []string{"Some text before the file.", "", "begin 600 one.bin"}
+		lines = append(lines, encode(uuCharacters, one)...)
+		lines = append(lines, "end", "", "-- ", "a signature", "begin 0755 two files.bin")
+		lines = append(lines, encode(xxCharacters, two)...)
+		lines = append(lines, "end")
+
+		decoder := NewDecoder(join(lines...))
+
+		header, err := decoder.Next()
+		require.Nil(t, err)
+		assert.Equal(t, &Header{Name: "one.bin", Mode: 0600, Encoding: UuEncoding}, header)
+		data, err := io.ReadAll(decoder)
+		require.Nil(t, err)
+		assert.Equal(t, one, data)
+
+		header, err = decoder.Next()
+		require.Nil(t, err)
+		assert.Equal(t, &Header{Name: "two files.bin", Mode: 0755, Encoding: XxEncoding}, header)
+		data, err = io.ReadAll(decoder)
+		require.Nil(t, err)
+		assert.Equal(t, two, data)
+
+		_, err = decoder.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("skips unread content", func(t *testing.T) {
+		lines := []string{"begin 644 one"}
+		lines = append(lines, encode(uuCharacters, testData(100))...)
+		lines = append(lines, "end", "begin 644 two")
+		lines = append(lines, encode(uuCharacters, []byte("two"))...)
+		lines = append(lines, "end")
+
+		decoder := NewDecoder(join(lines...))
+		_, err := decoder.Next()
+		require.Nil(t, err)
+		buf := make([]byte, 10)
+		_, err = decoder.Read(buf)
+		require.Nil(t, err)
+
+		header, err := decoder.Next()
+		require.Nil(t, err)
+		assert.Equal(t, "two", header.Name)
+		data, err := io.ReadAll(decoder)
+		require.Nil(t, err)
+		assert.Equal(t, "two", string(data))
+	})
+
+	t.Run("decodes posts split across articles", func(t *testing.T) {
+		data := testData(500)
+		encoded := encode(uuCharacters, data)
+
+		first := join(append([]string{"Part 1 of 2", "", "begin 644 split.bin"}, encoded[:6]...)...)
+		second := join(append([]string{"Part 2 of 2", "", "-- cut here --"}, append(encoded[6:], "end", "", "-- ", "sig")...)...)
+
+		files, err := DecodeAll(io.MultiReader(first, second))
+		require.Nil(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, data, files[0].Data)
+	})
+
+	t.Run("tolerates mangled lines", func(t *testing.T) {
+		spaces := " " + uuCharacters[1:]
+		middle := testData(14)
+		// The trailing space of the first line has been stripped, the second
+		// line starts with a dot and has been dot-stuffed, and the third line
+		// has gained trailing whitespace.
+		lines := []string{
+			"begin 644 mangled",
+			strings.TrimRight(encode(spaces, []byte("ab\x00"))[0], " "),
+			"." + encode(spaces, middle)[0],
+			encode(spaces, []byte("xyz"))[0] + "  \t",
+			"end",
+		}
+		require.Equal(t, "#86(", lines[1])
+		require.Equal(t, "..", lines[2][:2])
+
+		files, err := DecodeAll(join(lines...))
+		require.Nil(t, err)
+		require.Len(t, files, 1)
+
+		expected := append(append([]byte("ab\x00"), middle...), "xyz"...)
+		assert.Equal(t, expected, files[0].Data)
+	})
+
+	t.Run("reports a missing end line", func(t *testing.T) {
+		lines := append([]string{"begin 644 short.bin"}, encode(uuCharacters, []byte("partial"))...)
+		files, err := DecodeAll(join(lines...))
+		assert.Equal(t, true, errors.Is(err, ErrMissingEnd))
+		assert.ErrorContains(t, err, "short.bin: uudecode: missing end line")
+		require.Len(t, files, 1)
+		assert.Equal(t, "partial", string(files[0].Data))
+	})
+
+	t.Run("ignores invalid begin lines", func(t *testing.T) {
+		files, err := DecodeAll(join("begin the story", "begin 9999 foo", "begin 644", "the end"))
+		assert.Nil(t, err)
+		assert.Len(t, files, 0)
+	})
+
+	t.Run("reads nothing without a file", func(t *testing.T) {
+		decoder := NewDecoder(bytes.NewReader(nil))
+		n, err := decoder.Read(make([]byte, 1))
+		assert.Equal(t, 0, n)
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func Test_decodeLine(t *testing.T) {
+	uu := alphabets[UuEncoding]
+
+	decoded, ok := decodeLine(uu, []byte("#0V%T"), nil)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Cat", string(decoded))
+
+	// With a checksum character.
+	decoded, ok = decodeLine(uu, []byte("#0V%TX"), []byte("a"))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "aCat", string(decoded))
+
+	for _, line := range []string{"", "#0V%TXX", "M too short", "#0v%T", "hello world"} {
+		_, ok = decodeLine(uu, []byte(line), nil)
+		assert.Equal(t, false, ok, line)
+	}
+
+	_, ok = decodeLine(alphabets[XxEncoding], []byte("1Ek7ZJ"), nil)
+	assert.Equal(t, true, ok)
+}
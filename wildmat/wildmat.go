@@ -0,0 +1,285 @@
+// Package wildmat implements the wildmat pattern format of RFC 3977 §4, which
+// is accepted by commands such as `LIST ACTIVE` and `NEWNEWS`, so that group
+// names can also be matched locally:
+//
+//	w, err := wildmat.Compile("comp.*,!comp.os.*,comp.os.linux")
+//	// ...
+//	w.Match("comp.lang.go")     // true
+//	w.Match("comp.os.windows")  // false
+//	w.Match("comp.os.linux")    // true
+//
+// A wildmat is a comma-separated list of patterns, each of which may be
+// negated with a leading `!`. The patterns are tried in order and the last
+// pattern that matches decides: the string matches if that pattern is not
+// negated. A string that no pattern matches does not match.
+//
+// Within a pattern, `*` matches any sequence of characters, including none,
+// and `?` matches exactly one character. As in INN, character classes such
+// as `[a-z]` and `[^0-9]` are supported as well, and `\` matches the
+// following character literally. These extensions are not part of RFC 3977
+// and may not be understood by all servers.
+package wildmat
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError describes why a wildmat is invalid.
+type SyntaxError struct {
+	// Wildmat is the complete wildmat that was compiled.
+	Wildmat string
+	// Offset is the byte offset of the problem in Wildmat.
+	Offset int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("wildmat: %s at offset %d in %q", e.Reason, e.Offset, e.Wildmat)
+}
+
+type tokenKind int
+
+const (
+	literal tokenKind = iota
+	anyOne
+	anySequence
+	class
+)
+
+type runeRange struct {
+	low  rune
+	high rune
+}
+
+type token struct {
+	kind    tokenKind
+	r       rune
+	negated bool
+	ranges  []runeRange
+}
+
+// matches indicates if a single character matches a literal, `?`, or class
+// token.
+func (t *token) matches(r rune) bool {
+	switch t.kind {
+	case literal:
+		return r == t.r
+	case anyOne:
+		return true
+	case class:
+		for _, rr := range t.ranges {
+			if r >= rr.low && r <= rr.high {
+				return !t.negated
+			}
+		}
+		return t.negated
+	}
+	return false
+}
+
+type pattern struct {
+	negated bool
+	tokens  []token
+}
+
+// match reports if the pattern matches all of s.
+func (p *pattern) match(s []rune) bool {
+	// Greedy matching that backtracks to the most recent `*` only, which is
+	// sufficient because `*` matches any sequence.
+	ti, si := 0, 0
+	starToken, starString := -1, 0
+	for si < len(s) {
+		switch {
+		case ti < len(p.tokens) && p.tokens[ti].kind == anySequence:
+			starToken, starString = ti, si
+			ti++
+		case ti < len(p.tokens) && p.tokens[ti].matches(s[si]):
+			ti++
+			si++
+		case starToken >= 0:
+			starString++
+			ti, si = starToken+1, starString
+		default:
+			return false
+		}
+	}
+	for ti < len(p.tokens) && p.tokens[ti].kind == anySequence {
+		ti++
+	}
+	return ti == len(p.tokens)
+}
+
+// Wildmat is a compiled wildmat. It is safe for concurrent use.
+type Wildmat struct {
+	source   string
+	patterns []pattern
+}
+
+// Compile parses a wildmat. A [*SyntaxError] is returned if it is invalid,
+// e.g. if it contains an empty pattern, whitespace, or an unterminated
+// character class.
+func Compile(wildmat string) (*Wildmat, error) {
+	c := compiler{source: wildmat}
+	w := &Wildmat{source: wildmat}
+	for {
+		p, err := c.pattern()
+		if err != nil {
+			return nil, err
+		}
+		w.patterns = append(w.patterns, p)
+		if c.offset == len(wildmat) {
+			return w, nil
+		}
+		// The pattern ended at a comma.
+		c.offset++
+	}
+}
+
+// MustCompile is like [Compile] but panics if the wildmat is invalid.
+func MustCompile(wildmat string) *Wildmat {
+	w, err := Compile(wildmat)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// Match compiles the wildmat and matches s against it.
+func Match(wildmat string, s string) (bool, error) {
+	w, err := Compile(wildmat)
+	if err != nil {
+		return false, err
+	}
+	return w.Match(s), nil
+}
+
+// Match indicates if s matches the wildmat.
+func (w *Wildmat) Match(s string) bool {
+	runes := []rune(s)
+	for i := len(w.patterns) - 1; i >= 0; i-- {
+		if w.patterns[i].match(runes) {
+			return !w.patterns[i].negated
+		}
+	}
+	return false
+}
+
+// String returns the wildmat as it was compiled.
+func (w *Wildmat) String() string {
+	return w.source
+}
+
+type compiler struct {
+	source string
+	offset int
+}
+
+func (c *compiler) fail(offset int, format string, args ...any) error {
+	return &SyntaxError{Wildmat: c.source, Offset: offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+// next returns the character at the current offset and advances past it.
+func (c *compiler) next() (rune, error) {
+	r, size := utf8.DecodeRuneInString(c.source[c.offset:])
+	if r == utf8.RuneError && size <= 1 {
+		return 0, c.fail(c.offset, "invalid UTF-8")
+	}
+	c.offset += size
+	return r, nil
+}
+
+// pattern parses a single pattern up to the next comma or the end of the
+// wildmat.
+func (c *compiler) pattern() (pattern, error) {
+	var p pattern
+	start := c.offset
+	if strings.HasPrefix(c.source[c.offset:], "!") {
+		p.negated = true
+		c.offset++
+	}
+
+	for c.offset < len(c.source) && c.source[c.offset] != ',' {
+		offset := c.offset
+		r, err := c.next()
+		if err != nil {
+			return p, err
+		}
+
+		switch {
+		case r == '*':
+			// Consecutive stars are equivalent to a single one.
+			if n := len(p.tokens); n == 0 || p.tokens[n-1].kind != anySequence {
+				p.tokens = append(p.tokens, token{kind: anySequence})
+			}
+		case r == '?':
+			p.tokens = append(p.tokens, token{kind: anyOne})
+		case r == '[':
+			t, err := c.class(offset)
+			if err != nil {
+				return p, err
+			}
+			p.tokens = append(p.tokens, t)
+		case r == '\\':
+			if c.offset == len(c.source) {
+				return p, c.fail(offset, "trailing backslash")
+			}
+			escaped, err := c.next()
+			if err != nil {
+				return p, err
+			}
+			p.tokens = append(p.tokens, token{kind: literal, r: escaped})
+		case r == '!' || r == ']':
+			return p, c.fail(offset, "unexpected %q", r)
+		case r <= ' ' || r == 0x7f:
+			return p, c.fail(offset, "invalid character %q", r)
+		default:
+			p.tokens = append(p.tokens, token{kind: literal, r: r})
+		}
+	}
+
+	if len(p.tokens) == 0 {
+		return p, c.fail(start, "empty pattern")
+	}
+	return p, nil
+}
+
+// class parses a character class whose opening bracket is at start. A `^`
+// directly after the bracket negates the class, and a `]` directly after
+// that is part of the class.
+func (c *compiler) class(start int) (token, error) {
+	t := token{kind: class}
+	if strings.HasPrefix(c.source[c.offset:], "^") {
+		t.negated = true
+		c.offset++
+	}
+
+	first := true
+	for {
+		if c.offset == len(c.source) {
+			return t, c.fail(start, "unterminated character class")
+		}
+		low, err := c.next()
+		if err != nil {
+			return t, err
+		}
+		if low == ']' && !first {
+			return t, nil
+		}
+		first = false
+
+		high := low
+		if strings.HasPrefix(c.source[c.offset:], "-") && c.offset+1 < len(c.source) && c.source[c.offset+1] != ']' {
+			offset := c.offset
+			c.offset++
+			if high, err = c.next(); err != nil {
+				return t, err
+			}
+			if high < low {
+				return t, c.fail(offset, "invalid range %q", string([]rune{low, '-', high}))
+			}
+		}
+		t.ranges = append(t.ranges, runeRange{low: low, high: high})
+	}
+}
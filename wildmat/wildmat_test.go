@@ -0,0 +1,114 @@
+package wildmat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Match(t *testing.T) {
+	tests := []struct {
+		wildmat  string
+		input    string
+		expected bool
+	}{
+		{"comp.lang.go", "comp.lang.go", true},
+		{"comp.lang.go", "comp.lang.golang", false},
+		{"comp.lang.go", "Comp.lang.go", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"comp.*", "comp.lang.go", true},
+		{"comp.*", "comp.", true},
+		{"comp.*", "comp", false},
+		{"*.go", "comp.lang.go", true},
+		{"*lang*", "comp.lang.go", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{"a**b", "ab", true},
+		{"?", "", false},
+		{"?", "é", true},
+		{"comp.lang.?o", "comp.lang.go", true},
+		{"comp.lang.?o", "comp.lang.gopher", false},
+		{"alt.[abc]*", "alt.binaries", true},
+		{"alt.[abc]*", "alt.test", false},
+		{"alt.[a-c]*", "alt.binaries", true},
+		{"alt.[^a-c]*", "alt.binaries", false},
+		{"alt.[^a-c]*", "alt.test", true},
+		{"[]]", "]", true},
+		{"[^]]", "]", false},
+		{"[a-]", "-", true},
+		{"[,]", ",", true},
+		{`\*`, "*", true},
+		{`\*`, "x", false},
+		// Pattern lists, where the last matching pattern decides.
+		{"comp.*,!comp.os.*", "comp.lang.go", true},
+		{"comp.*,!comp.os.*", "comp.os.linux", false},
+		{"comp.*,!comp.os.*,comp.os.linux", "comp.os.linux", true},
+		{"comp.*,!comp.os.*,comp.os.linux", "comp.os.windows", false},
+		{"!comp.os.*,comp.*", "comp.os.linux", true},
+		{"!comp.*", "comp.lang.go", false},
+		{"!comp.*", "alt.test", false},
+		{"alt.*,comp.*", "sci.math", false},
+	}
+
+	for _, test := range tests {
+		actual, err := Match(test.wildmat, test.input)
+		require.Nil(t, err, test.wildmat)
+		assert.Equal(t, test.expected, actual, "%s against %s", test.input, test.wildmat)
+	}
+}
+
+func Test_Compile(t *testing.T) {
+	t.Run("keeps the source", func(t *testing.T) {
+		w, err := Compile("comp.*,!comp.os.*")
+		require.Nil(t, err)
+		assert.Equal(t, "comp.*,!comp.os.*", w.String())
+	})
+
+	t.Run("rejects invalid wildmats", func(t *testing.T) {
+		tests := []struct {
+			wildmat string
+			offset  int
+			reason  string
+		}{
+			{"", 0, "empty pattern"},
+			{"!", 0, "empty pattern"},
+			{"a,,b", 2, "empty pattern"},
+			{"a,", 2, "empty pattern"},
+			{"comp.!os", 5, "unexpected '!'"},
+			{"comp]", 4, "unexpected ']'"},
+			{"comp lang", 4, "invalid character ' '"},
+			{"comp.[a-", 5, "unterminated character class"},
+			{"[]", 0, "unterminated character class"},
+			{"[z-a]", 2, `invalid range "z-a"`},
+			{`foo\`, 3, "trailing backslash"},
+			{"a\xffb", 1, "invalid UTF-8"},
+		}
+
+		for _, test := range tests {
+			w, err := Compile(test.wildmat)
+			assert.Nil(t, w, test.wildmat)
+
+			var syntaxError *SyntaxError
+			require.Equal(t, true, errors.As(err, &syntaxError), test.wildmat)
+			assert.Equal(t, test.wildmat, syntaxError.Wildmat)
+			assert.Equal(t, test.offset, syntaxError.Offset, test.wildmat)
+			assert.Equal(t, test.reason, syntaxError.Reason, test.wildmat)
+		}
+	})
+
+	t.Run("formats errors", func(t *testing.T) {
+		_, err := Compile("a,,b")
+		assert.EqualError(t, err, `wildmat: empty pattern at offset 2 in "a,,b"`)
+
+		_, err = Match("a,,b", "a")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("panics on invalid wildmats", func(t *testing.T) {
+		assert.Panics(t, func() { MustCompile("a,,b") })
+		assert.NotPanics(t, func() { MustCompile("a,b") })
+	})
+}
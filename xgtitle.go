@@ -0,0 +1,37 @@
+package nntpclient
+
+import "fmt"
+
+// XGTitle retrieves the descriptions of the groups matching the wildmat with
+// the `XGTITLE` extension of RFC 2980 §2.6. It is supported by servers that
+// predate `LIST NEWSGROUPS`, which [Client.ListNewsgroups] falls back to
+// automatically. The wildmat parameter can be the empty string to indicate
+// "all groups." Malformed lines are reported as described for
+// [Client.ListActive].
+func (c *Client) XGTitle(wildmat string) (map[string]ListNewsgroup, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]ListNewsgroup)
+	err := c.xgtitle(wildmat, collectByName(result, func(group ListNewsgroup) string { return group.Name }))
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+func (c *Client) xgtitle(wildmat string, fn func(ListNewsgroup) error) error {
+	cmd := "XGTITLE"
+	if wildmat != "" {
+		cmd = fmt.Sprintf("XGTITLE %s", wildmat)
+	}
+
+	code, message, err := c.sendCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if code != 282 {
+		return UnexpectedError(code, message)
+	}
+	return readParsed(c, parseListNewsgroup, fn)
+}
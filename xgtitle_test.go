@@ -0,0 +1,42 @@
+package nntpclient
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_XGTitle(t *testing.T) {
+	t.Run("returns descriptions", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			assert.Equal(t, "xgtitle", cmd)
+			assert.Equal(t, []string{"misc.*"}, params)
+			writeLines(c, "282 list follows", "misc.test General Usenet testing", "misc.misc", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		list, err := client.XGTitle("misc.*")
+		assert.Nil(t, err)
+
+		expected := map[string]ListNewsgroup{
+			"misc.test": {Name: "misc.test", Description: "General Usenet testing"},
+			"misc.misc": {Name: "misc.misc", Description: ""},
+		}
+		assert.Equal(t, expected, list)
+	})
+
+	t.Run("handles unexpected response code", func(t *testing.T) {
+		c := Client{
+			conn: responseConn{response: &singleLineReader{line: "481 unavailable\r\n"}},
+		}
+
+		list, err := c.XGTitle("")
+		assert.Nil(t, list)
+		assert.Equal(t, true, errors.Is(err, NntpError))
+		assert.ErrorContains(t, err, "unexpected response code: 481 (unavailable)")
+	})
+}
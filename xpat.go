@@ -0,0 +1,83 @@
+package nntpclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// XPatMatch is an article whose header matched an `XPAT` query.
+type XPatMatch struct {
+	// Number is the number of the article in the current group. It is `0` if
+	// the article was requested by message-id.
+	Number int
+	// MessageId is the message-id of the article if it was requested by
+	// message-id. Otherwise, it is empty.
+	MessageId string
+	// Value is the value of the matching header.
+	Value string
+}
+
+// XPat searches the named header of articles for values that match any of
+// the wildmat patterns, using the `XPAT` extension of RFC 2980 §2.9. It is
+// supported by servers that predate `HDR`. The id may be a message-id, a
+// single article number, or a range of article numbers in the current group,
+// e.g. `100-200` or `100-`. Patterns are matched against the complete header
+// value, so `*foo*` is needed to find `foo` anywhere in the value.
+//
+// Malformed lines are reported as described for [Client.ListActive].
+func (c *Client) XPat(header string, id string, patterns ...string) ([]XPatMatch, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.requireCapability("READER"); err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("xpat: at least one pattern is required")
+	}
+
+	cmd := fmt.Sprintf("XPAT %s %s %s", header, id, strings.Join(patterns, " "))
+	code, message, err := c.sendCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code {
+	case 412:
+		return nil, ErrNoGroupSelected
+	case 420:
+		return nil, ErrCurrentArticleNumInvalid
+	case 423:
+		return nil, ErrNoArticleWithNum
+	case 430:
+		return nil, ErrNoArticleWithId
+	}
+	if code != 221 {
+		return nil, UnexpectedError(code, message)
+	}
+
+	result := make([]XPatMatch, 0)
+	err = readParsed(c, parseXPatMatch, func(match XPatMatch) error {
+		result = append(result, match)
+		return nil
+	})
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+	return result, err
+}
+
+// parseXPatMatch parses a line of an `XPAT` response, which holds the article
+// number, or message-id, and the header value separated by a space.
+func parseXPatMatch(line string) (XPatMatch, error) {
+	key, value, _ := strings.Cut(line, " ")
+	if strings.HasPrefix(key, "<") {
+		return XPatMatch{MessageId: key, Value: value}, nil
+	}
+	number, err := parseNumber(key, "article number")
+	if err != nil {
+		return XPatMatch{}, err
+	}
+	return XPatMatch{Number: number, Value: value}, nil
+}
@@ -0,0 +1,85 @@
+package nntpclient
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_XPat(t *testing.T) {
+	t.Run("returns matches", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			assert.Equal(t, "xpat", cmd)
+			assert.Equal(t, []string{"Subject", "100-", "*go*", "*Go*"}, params)
+			writeLines(c, "221 header follows", "101 Learning go", "105 Go 1.23 released", "106", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		matches, err := client.XPat("Subject", "100-", "*go*", "*Go*")
+		require.Nil(t, err)
+
+		expected := []XPatMatch{
+			{Number: 101, Value: "Learning go"},
+			{Number: 105, Value: "Go 1.23 released"},
+			{Number: 106, Value: ""},
+		}
+		assert.Equal(t, expected, matches)
+	})
+
+	t.Run("returns matches by message-id", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "221 header follows", "<a@example> Learning go", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		matches, err := client.XPat("Subject", "<a@example>", "*")
+		require.Nil(t, err)
+		assert.Equal(t, []XPatMatch{{MessageId: "<a@example>", Value: "Learning go"}}, matches)
+	})
+
+	t.Run("reports malformed lines", func(t *testing.T) {
+		handler := func(t *testing.T, c net.Conn, cmd string, params []string) {
+			writeLines(c, "221 header follows", "one Learning go", "2 Go", ".")
+		}
+
+		server, client := getServerAndClient(t, handler)
+		defer server.Close()
+
+		matches, err := client.XPat("Subject", "1-2", "*")
+		assert.Equal(t, []XPatMatch{{Number: 2, Value: "Go"}}, matches)
+		assert.ErrorContains(t, err, `invalid article number "one"`)
+	})
+
+	t.Run("requires a pattern", func(t *testing.T) {
+		c := Client{}
+		matches, err := c.XPat("Subject", "1-2")
+		assert.Nil(t, matches)
+		assert.ErrorContains(t, err, "at least one pattern is required")
+	})
+
+	t.Run("handles error responses", func(t *testing.T) {
+		tests := map[string]error{
+			"412 no group selected\r\n":    ErrNoGroupSelected,
+			"420 no current article\r\n":   ErrCurrentArticleNumInvalid,
+			"423 no such article\r\n":      ErrNoArticleWithNum,
+			"430 no article with that\r\n": ErrNoArticleWithId,
+			"500 what?\r\n":                NntpError,
+		}
+		for line, expected := range tests {
+			c := Client{
+				conn: responseConn{response: &singleLineReader{line: line}},
+			}
+
+			matches, err := c.XPat("Subject", "1-2", "*")
+			assert.Nil(t, matches)
+			assert.Equal(t, true, errors.Is(err, expected), line)
+		}
+	})
+}
@@ -60,7 +60,7 @@ func NewTestServer(t *testing.T, handler commandHandler) (*TestServer, error) {
 			conn, err := listener.Accept()
 			if err != nil {
 				if errors.Is(err, net.ErrClosed) {
-					continue
+					return
 				}
 				panic(err)
 			}